@@ -0,0 +1,114 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"math/big"
+)
+
+// AccessListCreator - backend able to generate access list for transaction (eth_createAccessList).
+// Signature matches go-ethereum's gethclient.Client: access list, gas used with it, execution error (if any).
+//
+// For helpers connected to RPC node (NewEIP1559TxHelper) gethclient is used automatically, custom backends
+// may implement this interface to support access lists.
+type AccessListCreator interface {
+	CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*types.AccessList, uint64, string, error)
+}
+
+// accessListCreator returns backend's AccessListCreator, nil if access lists are not supported
+func (eipHelper *EIP1559TransactionHelper) accessListCreator() AccessListCreator {
+	if creator, ok := eipHelper.backend.(AccessListCreator); ok {
+		return creator
+	}
+
+	if eipHelper.ethClient != nil {
+		return gethclient.New(eipHelper.ethClient.Client())
+	}
+
+	return nil
+}
+
+// CreateAccessList generates access list for transaction via eth_createAccessList (at pending state).
+// Returns the list and gas transaction used with it.
+func (eipHelper *EIP1559TransactionHelper) CreateAccessList(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte) (types.AccessList, uint64, error) {
+	creator := eipHelper.accessListCreator()
+	if creator == nil {
+		return nil, 0, fmt.Errorf("backend does not support eth_createAccessList")
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Value: value,
+		Data:  data,
+	}
+
+	accessList, gasUsed, vmError, err := creator.CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, 0, WrapExternalError(err, "failed to create access list")
+	}
+
+	if vmError != "" {
+		if revertError := revertErrorFromNodeError(errors.New(vmError), eipHelper.revertABIs...); revertError != nil {
+			return nil, 0, WrapExternalError(revertError, "failed to create access list")
+		}
+
+		return nil, 0, WrapExternalError(errors.New(vmError), "failed to create access list")
+	}
+
+	if accessList == nil {
+		return types.AccessList{}, gasUsed, nil
+	}
+
+	return *accessList, gasUsed, nil
+}
+
+// GetGasParametersWithAccessList - same as GetGasParametersWithContext, but also generates access list (eth_createAccessList)
+// and estimates gas with it. The list is attached to returned gas params (and so to transaction built from them)
+// only if it makes transaction cheaper, otherwise gas params without the list are returned.
+// If the list can't be used (backend does not support eth_createAccessList, node returns error or execution fails
+// while generating or estimating it), gas params without the list are returned as well - use CreateAccessList to see the error.
+//
+// Legacy transactions can't carry access list, for them (see WithTxType) gas params are returned as is.
+// In emulation mode emulated gas params are returned.
+func (eipHelper *EIP1559TransactionHelper) GetGasParametersWithAccessList(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte) (Gas1559Params, error) {
+	gasParams, err := eipHelper.GetGasParametersWithContext(ctx, from, to, value, data)
+	if err != nil {
+		return Gas1559Params{}, err
+	}
+
+	if eipHelper.emulation || eipHelper.accessListOptimization {
+		return gasParams, nil // Emulated params, or list already applied by GetGasParametersWithContext
+	}
+
+	return eipHelper.withAccessList(ctx, gasParams, from, to, value, data), nil
+}
+
+// withAccessList attaches generated access list to gas params, if it lowers gas (see GetGasParametersWithAccessList).
+// The list is only an optimization, so any failure to get it leaves gas params without the list.
+func (eipHelper *EIP1559TransactionHelper) withAccessList(ctx context.Context, gasParams Gas1559Params, from common.Address, to *common.Address, value *big.Int, data []byte) Gas1559Params {
+	if gasParams.resolvedTxType() == TxTypeLegacy {
+		return gasParams
+	}
+
+	accessList, _, err := eipHelper.CreateAccessList(ctx, from, to, value, data)
+	if err != nil || len(accessList) == 0 {
+		return gasParams
+	}
+
+	// Every listed address and storage key costs intrinsic gas, so the list helps only if warm accesses save more
+	gasWithList, err := estimateGasWithAccessList(ctx, eipHelper.backend, from, to, value, data, accessList, eipHelper.revertABIs...)
+	if err != nil || gasWithList >= gasParams.Gas {
+		return gasParams
+	}
+
+	gasParams.Gas = gasWithList
+	gasParams.AccessList = accessList
+
+	return gasParams
+}
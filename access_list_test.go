@@ -0,0 +1,143 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeAccessListBackend - fakeDryRunBackend which also supports eth_createAccessList
+type fakeAccessListBackend struct {
+	fakeDryRunBackend
+
+	vmError string // Execution error returned by eth_createAccessList
+	err     error  // Node error returned by eth_createAccessList
+}
+
+var testAccessList = types.AccessList{{
+	Address:     common.HexToAddress("0x00000000000000000000000000000000000000cc"),
+	StorageKeys: []common.Hash{common.HexToHash("0x01")},
+}}
+
+func (backend *fakeAccessListBackend) CreateAccessList(_ context.Context, _ ethereum.CallMsg) (*types.AccessList, uint64, string, error) {
+	if backend.err != nil {
+		return nil, 0, "", backend.err
+	}
+
+	accessList := testAccessList
+
+	return &accessList, 25000, backend.vmError, nil
+}
+
+func TestGasParametersWithAccessList(t *testing.T) {
+	tests := []struct {
+		name           string
+		backend        TxHelperBackend
+		wantGas        uint64
+		wantAccessList bool
+	}{
+		{
+			name:           "list lowers gas",
+			backend:        &fakeAccessListBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}},
+			wantGas:        25000,
+			wantAccessList: true,
+		},
+		{
+			name:    "backend without eth_createAccessList",
+			backend: &fakeDryRunBackend{baseFee: big.NewInt(30)},
+			wantGas: 30000,
+		},
+		{
+			name:    "node error",
+			backend: &fakeAccessListBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}, err: errors.New("method not found")},
+			wantGas: 30000,
+		},
+		{
+			name:    "execution error",
+			backend: &fakeAccessListBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}, vmError: "execution reverted"},
+			wantGas: 30000,
+		},
+	}
+
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			helper, err := NewEIP1559TxHelperWithBackend(test.backend, WithAccessListOptimization(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gasParams, err := helper.GetGasParametersWithContext(context.Background(), from, &to, big.NewInt(0), nil)
+			if err != nil {
+				t.Fatalf("GetGasParametersWithContext failed: %s", err)
+			}
+
+			checkAccessListGasParams(t, gasParams, test.wantGas, test.wantAccessList)
+
+			// Explicit call goes the same way without the option
+			helper, err = NewEIP1559TxHelperWithBackend(test.backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gasParams, err = helper.GetGasParametersWithAccessList(context.Background(), from, &to, big.NewInt(0), nil)
+			if err != nil {
+				t.Fatalf("GetGasParametersWithAccessList failed: %s", err)
+			}
+
+			checkAccessListGasParams(t, gasParams, test.wantGas, test.wantAccessList)
+		})
+	}
+}
+
+func TestDryRunTransactionAccessListOptimization(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &fakeAccessListBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}}
+
+	helper, err := NewEIP1559TxHelperWithBackend(backend, WithAccessListOptimization(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	result, err := helper.DryRunTransaction(context.Background(), signer, &to, big.NewInt(1337), nil, big.NewInt(0), nil)
+	if err != nil {
+		t.Fatalf("dry-run failed: %s", err)
+	}
+
+	checkAccessListGasParams(t, result.GasParams, 25000, true)
+
+	if len(result.SignedTx.AccessList()) != len(testAccessList) {
+		t.Errorf("signed transaction has access list %v, want %v", result.SignedTx.AccessList(), testAccessList)
+	}
+}
+
+func checkAccessListGasParams(t *testing.T, gasParams Gas1559Params, wantGas uint64, wantAccessList bool) {
+	t.Helper()
+
+	if gasParams.Gas != wantGas {
+		t.Errorf("gas %d, want %d", gasParams.Gas, wantGas)
+	}
+
+	if hasList := len(gasParams.AccessList) > 0; hasList != wantAccessList {
+		t.Errorf("access list attached: %t, want %t", hasList, wantAccessList)
+	}
+}
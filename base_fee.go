@@ -0,0 +1,129 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// EIP-1559 constants of Ethereum mainnet, other chains may use their own (see WithBaseFeeParams)
+const (
+	DefaultBaseFeeElasticityMultiplier uint64 = 2
+	DefaultBaseFeeChangeDenominator    uint64 = 8
+)
+
+// CalcNextBaseFee calculates base fee of the next block by EIP-1559 formula: base fee moves towards keeping
+// gas used at target (gasLimit / elasticity), by at most 1/denominator per block.
+func CalcNextBaseFee(parentBaseFee *big.Int, parentGasUsed uint64, parentGasLimit uint64, elasticity uint64, denominator uint64) *big.Int {
+	if elasticity == 0 {
+		elasticity = DefaultBaseFeeElasticityMultiplier
+	}
+
+	if denominator == 0 {
+		denominator = DefaultBaseFeeChangeDenominator
+	}
+
+	gasTarget := parentGasLimit / elasticity
+
+	if gasTarget == 0 || parentGasUsed == gasTarget {
+		return new(big.Int).Set(parentBaseFee)
+	}
+
+	if parentGasUsed > gasTarget {
+		// baseFee + max(1, baseFee * (gasUsed - target) / target / denominator)
+		delta := new(big.Int).SetUint64(parentGasUsed - gasTarget)
+		delta.Mul(delta, parentBaseFee)
+		delta.Div(delta, new(big.Int).SetUint64(gasTarget))
+		delta.Div(delta, new(big.Int).SetUint64(denominator))
+
+		if delta.Sign() == 0 {
+			delta.SetInt64(1)
+		}
+
+		return delta.Add(delta, parentBaseFee)
+	}
+
+	// max(0, baseFee - baseFee * (target - gasUsed) / target / denominator)
+	delta := new(big.Int).SetUint64(gasTarget - parentGasUsed)
+	delta.Mul(delta, parentBaseFee)
+	delta.Div(delta, new(big.Int).SetUint64(gasTarget))
+	delta.Div(delta, new(big.Int).SetUint64(denominator))
+
+	nextBaseFee := new(big.Int).Sub(parentBaseFee, delta)
+	if nextBaseFee.Sign() < 0 {
+		nextBaseFee.SetInt64(0)
+	}
+
+	return nextBaseFee
+}
+
+// baseFeeAfterFullBlocks returns the highest base fee possible after given number of 100% full blocks:
+// every full block raises base fee by (elasticity - 1) / denominator (by 12.5% on mainnet)
+func baseFeeAfterFullBlocks(baseFee *big.Int, blocks uint64, elasticity uint64, denominator uint64) *big.Int {
+	result := new(big.Int).Set(baseFee)
+
+	for i := uint64(0); i < blocks; i++ {
+		increase := new(big.Int).Mul(result, new(big.Int).SetUint64(elasticity-1))
+		increase.Div(increase, new(big.Int).SetUint64(denominator))
+
+		if increase.Sign() == 0 {
+			increase.SetInt64(1)
+		}
+
+		result.Add(result, increase)
+	}
+
+	return result
+}
+
+// nextBaseFee - base fee of the block transaction is going to, calculated from the latest header
+func (eipHelper *EIP1559TransactionHelper) nextBaseFee(header *types.Header) *big.Int {
+	return CalcNextBaseFee(header.BaseFee, header.GasUsed, header.GasLimit, eipHelper.baseFeeElasticity, eipHelper.baseFeeDenominator)
+}
+
+// gasFeeCap calculates maxFeePerGas: 2*baseFee + tip by default, or, if headroom is set (see WithBaseFeeHeadroom),
+// predicted next block base fee raised by given number of full blocks + tip
+func (eipHelper *EIP1559TransactionHelper) gasFeeCap(header *types.Header, gasTipCap *big.Int) *big.Int {
+	if eipHelper.baseFeeHeadroomBlocks == nil {
+		return gasFeeCap(header.BaseFee, gasTipCap)
+	}
+
+	return eipHelper.gasFeeCapWithHeadroom(eipHelper.nextBaseFee(header), gasTipCap)
+}
+
+func (eipHelper *EIP1559TransactionHelper) gasFeeCapWithHeadroom(nextBaseFee *big.Int, gasTipCap *big.Int) *big.Int {
+	baseFee := baseFeeAfterFullBlocks(nextBaseFee, *eipHelper.baseFeeHeadroomBlocks, eipHelper.baseFeeElasticity, eipHelper.baseFeeDenominator)
+
+	return baseFee.Add(baseFee, gasTipCap)
+}
+
+// PredictNextBaseFee - same as PredictNextBaseFeeWithContext, but uses default context (see WithDefaultTimeout)
+func (eipHelper *EIP1559TransactionHelper) PredictNextBaseFee() (*big.Int, error) {
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.PredictNextBaseFeeWithContext(ctx)
+}
+
+// PredictNextBaseFeeWithContext calculates base fee of the next block from the latest header (gas used vs target),
+// using EIP-1559 parameters given by WithBaseFeeParams (mainnet ones by default). Returns 0 for chains without EIP-1559.
+// In emulation mode base fee set in emulator (if any) is returned as is.
+func (eipHelper *EIP1559TransactionHelper) PredictNextBaseFeeWithContext(ctx context.Context) (*big.Int, error) {
+	if eipHelper.emulation {
+		if baseFee := eipHelper.emulator.emulatedBaseFee(); baseFee != nil {
+			return baseFee, nil
+		}
+	}
+
+	header, err := eipHelper.backend.HeaderByNumber(ctx, nil)
+
+	if err != nil {
+		return nil, WrapExternalError(err, "failed to request last block header")
+	}
+
+	if header.BaseFee == nil {
+		return big.NewInt(0), nil
+	}
+
+	return eipHelper.nextBaseFee(header), nil
+}
@@ -0,0 +1,94 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCalcNextBaseFeeMatchesGeth(t *testing.T) {
+	const gasLimit uint64 = 30_000_000
+
+	for _, baseFee := range []int64{0, 1, 7, 1_000, 1_000_000_000, 123_456_789_012} {
+		for _, gasUsed := range []uint64{0, gasLimit / 6, gasLimit / 2, gasLimit * 2 / 3, gasLimit - 1, gasLimit} {
+			t.Run(fmt.Sprintf("baseFee=%d,gasUsed=%d", baseFee, gasUsed), func(t *testing.T) {
+				parent := &types.Header{
+					Number:   big.NewInt(100),
+					GasLimit: gasLimit,
+					GasUsed:  gasUsed,
+					BaseFee:  big.NewInt(baseFee),
+				}
+
+				want := eip1559.CalcBaseFee(params.AllEthashProtocolChanges, parent)
+				got := CalcNextBaseFee(parent.BaseFee, gasUsed, gasLimit, DefaultBaseFeeElasticityMultiplier, DefaultBaseFeeChangeDenominator)
+
+				if got.Cmp(want) != 0 {
+					t.Fatalf("next base fee %s, geth calculates %s", got, want)
+				}
+
+				// Zero parameters mean mainnet ones
+				if defaults := CalcNextBaseFee(parent.BaseFee, gasUsed, gasLimit, 0, 0); defaults.Cmp(want) != 0 {
+					t.Fatalf("next base fee with default parameters %s, geth calculates %s", defaults, want)
+				}
+			})
+		}
+	}
+}
+
+func TestBaseFeeAfterFullBlocks(t *testing.T) {
+	const gasLimit uint64 = 30_000_000
+
+	for _, baseFee := range []int64{1, 7, 1_000_000_000} {
+		expected := big.NewInt(baseFee)
+
+		for blocks := uint64(0); blocks <= 6; blocks++ {
+			got := baseFeeAfterFullBlocks(big.NewInt(baseFee), blocks, DefaultBaseFeeElasticityMultiplier, DefaultBaseFeeChangeDenominator)
+
+			if got.Cmp(expected) != 0 {
+				t.Fatalf("base fee %d after %d full blocks: %s, want %s", baseFee, blocks, got, expected)
+			}
+
+			expected = CalcNextBaseFee(expected, gasLimit, gasLimit, DefaultBaseFeeElasticityMultiplier, DefaultBaseFeeChangeDenominator)
+		}
+	}
+}
+
+func TestGasFeeCapHeadroom(t *testing.T) {
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	// Latest block is exactly at target, so next base fee equals current one (30), tip is 2
+	tests := []struct {
+		name       string
+		opts       []TxHelperOption
+		wantFeeCap int64
+	}{
+		{name: "default 2*baseFee", wantFeeCap: 62},
+		{name: "headroom 0", opts: []TxHelperOption{WithBaseFeeHeadroom(0)}, wantFeeCap: 32},
+		{name: "headroom 2", opts: []TxHelperOption{WithBaseFeeHeadroom(2)}, wantFeeCap: 39}, // 30 -> 33 -> 37, + tip
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txHelper, err := NewEIP1559TxHelperWithBackend(&fakeDryRunBackend{baseFee: big.NewInt(30)}, append(test.opts, WithGasTip(2))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gasParams, err := txHelper.GetGasParametersWithContext(context.Background(), from, &to, big.NewInt(0), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if gasParams.GasTipCap.Cmp(big.NewInt(2)) != 0 || gasParams.GasFeeCap.Cmp(big.NewInt(test.wantFeeCap)) != 0 {
+				t.Fatalf("tip cap %s, fee cap %s, want 2 and %d", gasParams.GasTipCap, gasParams.GasFeeCap, test.wantFeeCap)
+			}
+		})
+	}
+}
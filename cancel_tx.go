@@ -0,0 +1,92 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// CancelTransaction cancels pending transaction with given nonce: it sends zero-value transfer to ourselves with
+// the same nonce and higher fees, so it replaces original transaction in mempool.
+//
+// Fees are calculated by GetGasParametersWithContext (current market) and raised at least by MinReplacementBumpPercent
+// relative to replacedGasParams (fees of transaction being cancelled), as nodes require for replacement. If replacedGasParams
+// is nil, cancelled transaction is assumed to be sent with current market fees, so they are bumped.
+// Cancelling transaction is of the type GetGasParametersWithContext prepares parameters for (see WithTxType).
+//
+// Returns receipt of the cancelling transaction. If original transaction is mined first, error is returned
+// (usually "nonce too low" on sending, or waiting interrupted by ctx).
+func (eipHelper *EIP1559TransactionHelper) CancelTransaction(
+	ctx context.Context,
+	signer Signer,
+	chainID *big.Int,
+	nonce uint64,
+	replacedGasParams *Gas1559Params,
+) (*types.Receipt, error) {
+
+	from := signer.Address()
+	value := big.NewInt(0)
+
+	if eipHelper.emulation {
+		return eipHelper.emulateSend(from, &from, chainID, Gas1559Params{GasTipCap: big.NewInt(0), GasFeeCap: big.NewInt(0)}, value, nil)
+	}
+
+	gasParams, err := eipHelper.GetGasParametersWithContext(ctx, from, &from, value, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if replacedGasParams == nil {
+		// Usually it is the case (e.g. fixed tip, see WithGasTip), and market fees alone would be "replacement transaction underpriced"
+		marketGasParams := gasParams
+		replacedGasParams = &marketGasParams
+	}
+
+	// Legacy and access list transactions pay gas price, node compares it with both tip and fee cap of replacement
+	replacedTip, replacedFeeCap := replacedGasParams.GasTipCap, replacedGasParams.GasFeeCap
+	if replacedGasParams.GasPrice != nil {
+		replacedTip, replacedFeeCap = replacedGasParams.GasPrice, replacedGasParams.GasPrice
+	}
+
+	if gasParams.resolvedTxType() == TxTypeDynamicFee {
+		if minTip := bumpByPercent(replacedTip, MinReplacementBumpPercent); gasParams.GasTipCap.Cmp(minTip) < 0 {
+			gasParams.GasTipCap = minTip
+		}
+
+		if minFeeCap := bumpByPercent(replacedFeeCap, MinReplacementBumpPercent); gasParams.GasFeeCap.Cmp(minFeeCap) < 0 {
+			gasParams.GasFeeCap = minFeeCap
+		}
+
+		if gasParams.GasFeeCap.Cmp(gasParams.GasTipCap) < 0 {
+			gasParams.GasFeeCap = new(big.Int).Set(gasParams.GasTipCap)
+		}
+	} else if minGasPrice := bumpByPercent(replacedFeeCap, MinReplacementBumpPercent); gasParams.gasPrice().Cmp(minGasPrice) < 0 {
+		gasParams = gasPriceParams(gasParams.TxType, minGasPrice, gasParams.Gas)
+	}
+
+	cancelTx, err := signer.SignTx(buildTransaction(chainID, nonce, gasParams, &from, value, nil), chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign cancelling transaction: %s", err)
+	}
+
+	if err = eipHelper.backend.SendTransaction(ctx, cancelTx); err != nil {
+		// "nonce too low" here means original transaction is already mined and can't be cancelled
+		return nil, &ExternalErrorWrapper{
+			OriginalError:     err,
+			OurMessage:        "failed to send cancelling transaction",
+			AdditionalContext: fmt.Sprintf("nonce: %d", nonce),
+		}
+	}
+
+	receipt, err := waitMined(ctx, eipHelper.backend, cancelTx.Hash())
+	if err != nil {
+		return nil, &ExternalErrorWrapper{
+			OriginalError:     err,
+			OurMessage:        "cancelling transaction probably has not been mined (timeout? or original transaction mined first)",
+			AdditionalContext: fmt.Sprintf("tx hash: %s, nonce: %d", cancelTx.Hash().Hex(), nonce),
+		}
+	}
+
+	return receipt, nil
+}
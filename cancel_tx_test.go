@@ -0,0 +1,143 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeSendingBackend - fakeDryRunBackend which accepts sent transactions and mines them at once
+type fakeSendingBackend struct {
+	fakeDryRunBackend
+
+	gasPrice *big.Int // Returned by eth_gasPrice
+
+	lock sync.Mutex
+	sent []*types.Transaction
+}
+
+func (backend *fakeSendingBackend) SuggestGasPrice(_ context.Context) (*big.Int, error) {
+	return backend.gasPrice, nil
+}
+
+func (backend *fakeSendingBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	backend.sent = append(backend.sent, tx)
+
+	return nil
+}
+
+func (backend *fakeSendingBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	for _, tx := range backend.sent {
+		if tx.Hash() == txHash {
+			return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+		}
+	}
+
+	return nil, ethereum.NotFound
+}
+
+func TestCancelTransactionBumpsFees(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Market: base fee 30, fixed tip 2, so fee cap is 2*30 + 2 = 62; gas price 50
+	tests := []struct {
+		name       string
+		txType     TxType
+		replaced   *Gas1559Params
+		wantTxType uint8
+		wantTipCap int64
+		wantFeeCap int64
+	}{
+		{
+			name:       "unknown replaced fees bump market fees",
+			replaced:   nil,
+			wantTxType: types.DynamicFeeTxType,
+			wantTipCap: 3, // 2 + 10% is still 2, so raised by 1
+			wantFeeCap: 68,
+		},
+		{
+			name:       "replaced fees above market",
+			replaced:   &Gas1559Params{GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(100)},
+			wantTxType: types.DynamicFeeTxType,
+			wantTipCap: 11,
+			wantFeeCap: 110,
+		},
+		{
+			name:       "replaced legacy transaction",
+			replaced:   &Gas1559Params{GasPrice: big.NewInt(100)},
+			wantTxType: types.DynamicFeeTxType,
+			wantTipCap: 110,
+			wantFeeCap: 110,
+		},
+		{
+			name:       "legacy, unknown replaced fees bump market gas price",
+			txType:     TxTypeLegacy,
+			replaced:   nil,
+			wantTxType: types.LegacyTxType,
+			wantTipCap: 55,
+			wantFeeCap: 55,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &fakeSendingBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}, gasPrice: big.NewInt(50)}
+
+			txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithGasTip(2), WithTxType(test.txType))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			receipt, err := txHelper.CancelTransaction(context.Background(), signer, big.NewInt(1337), 7, test.replaced)
+			if err != nil {
+				t.Fatalf("cancel failed: %s", err)
+			}
+
+			if len(backend.sent) != 1 {
+				t.Fatalf("expected 1 sent transaction, got %d", len(backend.sent))
+			}
+
+			cancelTx := backend.sent[0]
+
+			if receipt.TxHash != cancelTx.Hash() {
+				t.Errorf("receipt of %s, want %s", receipt.TxHash.Hex(), cancelTx.Hash().Hex())
+			}
+
+			if cancelTx.Type() != test.wantTxType {
+				t.Errorf("tx type %d, want %d", cancelTx.Type(), test.wantTxType)
+			}
+
+			if cancelTx.Nonce() != 7 || cancelTx.Value().Sign() != 0 || *cancelTx.To() != signer.Address() {
+				t.Errorf("expected zero-value self-transfer with nonce 7, got nonce %d, value %s, to %s", cancelTx.Nonce(), cancelTx.Value(), cancelTx.To().Hex())
+			}
+
+			if cancelTx.GasTipCap().Cmp(big.NewInt(test.wantTipCap)) != 0 {
+				t.Errorf("tip cap %s, want %d", cancelTx.GasTipCap(), test.wantTipCap)
+			}
+
+			if cancelTx.GasFeeCap().Cmp(big.NewInt(test.wantFeeCap)) != 0 {
+				t.Errorf("fee cap %s, want %d", cancelTx.GasFeeCap(), test.wantFeeCap)
+			}
+		})
+	}
+}
@@ -5,9 +5,10 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"math/big"
 )
 
@@ -22,17 +23,28 @@ func GetPublicAddressFromPrivateKey(privateKey *ecdsa.PrivateKey) (common.Addres
 	return crypto.PubkeyToAddress(*publicKeyECDSA), nil
 }
 
-func estimateGas(ethClient *ethclient.Client, from common.Address, to *common.Address, value *big.Int, data []byte) (gasLimit uint64, err error) {
+// estimateGas estimates gas limit, if execution reverts, returned error wraps *RevertError (custom errors are resolved from contractABIs)
+func estimateGas(ctx context.Context, backend TxHelperBackend, from common.Address, to *common.Address, value *big.Int, data []byte, contractABIs ...abi.ABI) (gasLimit uint64, err error) {
+	return estimateGasWithAccessList(ctx, backend, from, to, value, data, nil, contractABIs...)
+}
+
+// estimateGasWithAccessList - same as estimateGas, but transaction carries given access list (nil means no list)
+func estimateGasWithAccessList(ctx context.Context, backend TxHelperBackend, from common.Address, to *common.Address, value *big.Int, data []byte, accessList types.AccessList, contractABIs ...abi.ABI) (gasLimit uint64, err error) {
 	msg := ethereum.CallMsg{
-		From:  from,
-		To:    to,
-		Value: value,
-		Data:  data,
+		From:       from,
+		To:         to,
+		Value:      value,
+		Data:       data,
+		AccessList: accessList,
 	}
 
-	gasLimit, err = ethClient.EstimateGas(context.Background(), msg)
+	gasLimit, err = backend.EstimateGas(ctx, msg)
 
 	if err != nil {
+		if revertError := revertErrorFromNodeError(err, contractABIs...); revertError != nil {
+			return 0, WrapExternalError(revertError, "failed to estimate gas limit for given operation")
+		}
+
 		return 0, WrapExternalError(err, "failed to estimate gas limit for given operation")
 	}
 
@@ -0,0 +1,137 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"time"
+)
+
+// ReorgError is returned by WaitForConfirmations when block containing transaction is not canonical anymore.
+//
+// If transaction was re-included into another block, NewReceipt holds its new receipt (confirmations can be awaited again with it),
+// if transaction disappeared from the chain (returned to mempool or dropped), NewReceipt is nil.
+type ReorgError struct {
+	TxHash              common.Hash
+	OriginalBlockHash   common.Hash
+	OriginalBlockNumber *big.Int
+	NewReceipt          *types.Receipt
+}
+
+func (e *ReorgError) Error() string {
+	if e.NewReceipt == nil {
+		return fmt.Sprintf("chain reorganization: transaction %s disappeared from block %s (#%s)",
+			e.TxHash.Hex(), e.OriginalBlockHash.Hex(), e.OriginalBlockNumber)
+	}
+
+	return fmt.Sprintf("chain reorganization: transaction %s moved from block %s (#%s) to block %s (#%s)",
+		e.TxHash.Hex(), e.OriginalBlockHash.Hex(), e.OriginalBlockNumber, e.NewReceipt.BlockHash.Hex(), e.NewReceipt.BlockNumber)
+}
+
+// confirmationsPollInterval - how often new blocks are checked while waiting for confirmations
+const confirmationsPollInterval = time.Second
+
+// WaitForConfirmations waits until block containing transaction (described by receipt) has "confirmations" blocks on top
+// of it, counting the block itself (so 1 confirmation = just mined).
+//
+// On every check block hash from receipt is compared with canonical block at the same height, if they differ,
+// *ReorgError is returned (wrapped into ExternalErrorWrapper, use errors.As). In emulation mode receipt is returned as is.
+func (eipHelper *EIP1559TransactionHelper) WaitForConfirmations(ctx context.Context, receipt *types.Receipt, confirmations uint64) (*types.Receipt, error) {
+	if receipt == nil {
+		return nil, fmt.Errorf("receipt must not be nil")
+	}
+
+	if eipHelper.emulation {
+		return receipt, nil
+	}
+
+	return waitForConfirmations(ctx, eipHelper.backend, receipt, confirmations)
+}
+
+// WaitForConfirmations waits for transaction to be mined and then for given number of confirmations (see EIP1559TransactionHelper.WaitForConfirmations)
+func (pendingTx *PendingTx) WaitForConfirmations(ctx context.Context, confirmations uint64) (*types.Receipt, error) {
+	receipt, err := pendingTx.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingTx.backend == nil { // Emulation mode
+		return receipt, nil
+	}
+
+	return waitForConfirmations(ctx, pendingTx.backend, receipt, confirmations)
+}
+
+func waitForConfirmations(ctx context.Context, backend TxHelperBackend, receipt *types.Receipt, confirmations uint64) (*types.Receipt, error) {
+	if receipt.BlockNumber == nil {
+		return nil, fmt.Errorf("receipt has no block number, transaction is not mined")
+	}
+
+	ticker := time.NewTicker(confirmationsPollInterval)
+	defer ticker.Stop()
+
+	txBlockNumber := receipt.BlockNumber.Uint64()
+
+	for {
+		if err := checkReceiptIsCanonical(ctx, backend, receipt); err != nil {
+			return nil, err
+		}
+
+		latestBlockNumber, err := backend.BlockNumber(ctx)
+		if err != nil {
+			return nil, WrapExternalError(err, "failed to get latest block number")
+		}
+
+		if latestBlockNumber >= txBlockNumber && latestBlockNumber-txBlockNumber+1 >= confirmations {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &ExternalErrorWrapper{
+				OriginalError:     ctx.Err(),
+				OurMessage:        "transaction has not got enough confirmations",
+				AdditionalContext: fmt.Sprintf("tx hash: %s, block: %d, latest block: %d, confirmations needed: %d", receipt.TxHash.Hex(), txBlockNumber, latestBlockNumber, confirmations),
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReceiptIsCanonical compares receipt's block hash with canonical block hash at the same height
+func checkReceiptIsCanonical(ctx context.Context, backend TxHelperBackend, receipt *types.Receipt) error {
+	header, err := backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return WrapExternalError(err, fmt.Sprintf("failed to request block header #%s", receipt.BlockNumber))
+	}
+
+	if header != nil && header.Hash() == receipt.BlockHash {
+		return nil
+	}
+
+	// Block is not canonical anymore (or chain became shorter), let's check where transaction is now
+	reorgError := &ReorgError{
+		TxHash:              receipt.TxHash,
+		OriginalBlockHash:   receipt.BlockHash,
+		OriginalBlockNumber: receipt.BlockNumber,
+	}
+
+	newReceipt, err := backend.TransactionReceipt(ctx, receipt.TxHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return WrapExternalError(err, "failed to request transaction receipt after reorg detected")
+	}
+
+	if err == nil {
+		if newReceipt.BlockHash == receipt.BlockHash {
+			return nil // Node still reports transaction in the same block (header was not available or hashed differently)
+		}
+
+		reorgError.NewReceipt = newReceipt
+	}
+
+	return WrapExternalError(reorgError, "transaction is not in canonical chain anymore")
+}
@@ -0,0 +1,104 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// DryRunResult - everything SendTransaction would send, without sending it
+type DryRunResult struct {
+	SignedTx         *types.Transaction
+	Hash             common.Hash
+	Nonce            uint64
+	GasParams        Gas1559Params
+	PredictedFee     *big.Int // Gas * min(maxFeePerGas, next block baseFee + maxPriorityFeePerGas) (Gas * gasPrice for legacy and access list transactions), assuming all estimated gas is used
+	MaxFee           *big.Int // Gas * maxFeePerGas (Gas * gasPrice for legacy and access list transactions) - upper bound of the fee
+	MaxCost          *big.Int // MaxFee + value - balance needed to avoid "insufficient funds for gas * price + value"
+	SimulationResult []byte   // Data returned by eth_call of the signed transaction
+}
+
+// DryRunTransaction does all the real work of sending: fetches nonce, estimates gas (if gasParams is nil),
+// signs transaction and simulates it via eth_call at pending block, but does NOT broadcast it.
+// Nonce is not reserved in local nonce manager.
+//
+// Unlike emulation mode, dry-run always talks to backend, so it checks transaction construction for real.
+// If simulation reverts, returned error wraps *RevertError (use errors.As).
+func (eipHelper *EIP1559TransactionHelper) DryRunTransaction(
+	ctx context.Context,
+	signer Signer,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams *Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (*DryRunResult, error) {
+	from := signer.Address()
+
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	if gasParams == nil {
+		estimated, err := eipHelper.gasParametersFromNode(ctx, from, to, value, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if eipHelper.accessListOptimization {
+			estimated = eipHelper.withAccessList(ctx, estimated, from, to, value, data)
+		}
+
+		gasParams = &estimated
+	}
+
+	nonce, err := eipHelper.peekNonce(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := signer.SignTx(buildTransaction(chainID, nonce, *gasParams, to, value, data), chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %s", err)
+	}
+
+	simulationResult, err := eipHelper.SimulateTransaction(ctx, from, to, value, data, gasParams.Gas)
+	if err != nil {
+		return nil, err
+	}
+
+	baseFee, err := eipHelper.PredictNextBaseFeeWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gas := new(big.Int).SetUint64(gasParams.Gas)
+
+	// Legacy and access list transactions pay exactly gas price, their params may have no tip and fee cap at all
+	gasFeeCap := gasParams.gasPrice()
+	effectiveGasPrice := new(big.Int).Set(gasFeeCap)
+
+	if gasParams.resolvedTxType() == TxTypeDynamicFee {
+		gasFeeCap = gasParams.GasFeeCap
+
+		effectiveGasPrice.Add(baseFee, gasParams.GasTipCap)
+		if effectiveGasPrice.Cmp(gasFeeCap) > 0 {
+			effectiveGasPrice.Set(gasFeeCap)
+		}
+	}
+
+	maxFee := new(big.Int).Mul(gas, gasFeeCap)
+
+	return &DryRunResult{
+		SignedTx:         signedTx,
+		Hash:             signedTx.Hash(),
+		Nonce:            nonce,
+		GasParams:        *gasParams,
+		PredictedFee:     effectiveGasPrice.Mul(effectiveGasPrice, gas),
+		MaxFee:           maxFee,
+		MaxCost:          new(big.Int).Add(maxFee, value),
+		SimulationResult: simulationResult,
+	}, nil
+}
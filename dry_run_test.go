@@ -0,0 +1,134 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeDryRunBackend - node which answers only calls made by DryRunTransaction with given params
+type fakeDryRunBackend struct {
+	TxHelperBackend // Not used by dry-run, calls of other methods panic
+
+	baseFee *big.Int
+}
+
+func (backend *fakeDryRunBackend) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return &types.Header{
+		Number:   big.NewInt(1),
+		GasLimit: 30_000_000,
+		GasUsed:  15_000_000, // Exactly at target, so next base fee equals current one
+		BaseFee:  backend.baseFee,
+	}, nil
+}
+
+func (backend *fakeDryRunBackend) PendingNonceAt(_ context.Context, _ common.Address) (uint64, error) {
+	return 3, nil
+}
+
+func (backend *fakeDryRunBackend) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if len(msg.AccessList) > 0 {
+		return 25000, nil // Warm accesses make transaction cheaper
+	}
+
+	return 30000, nil
+}
+
+func (backend *fakeDryRunBackend) CallContract(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func TestDryRunTransactionFees(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chainID := big.NewInt(1337)
+	value := big.NewInt(1000)
+
+	tests := []struct {
+		name          string
+		gasParams     Gas1559Params
+		wantTxType    uint8
+		wantPredicted int64
+		wantMaxFee    int64
+	}{
+		{
+			name:          "legacy",
+			gasParams:     Gas1559Params{GasPrice: big.NewInt(50), Gas: 21000},
+			wantTxType:    types.LegacyTxType,
+			wantPredicted: 21000 * 50,
+			wantMaxFee:    21000 * 50,
+		},
+		{
+			name:          "access list",
+			gasParams:     Gas1559Params{TxType: TxTypeAccessList, GasPrice: big.NewInt(40), Gas: 21000},
+			wantTxType:    types.AccessListTxType,
+			wantPredicted: 21000 * 40,
+			wantMaxFee:    21000 * 40,
+		},
+		{
+			name:          "dynamic fee",
+			gasParams:     Gas1559Params{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(100), Gas: 21000},
+			wantTxType:    types.DynamicFeeTxType,
+			wantPredicted: 21000 * (30 + 2),
+			wantMaxFee:    21000 * 100,
+		},
+		{
+			name:          "dynamic fee capped",
+			gasParams:     Gas1559Params{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(31), Gas: 21000},
+			wantTxType:    types.DynamicFeeTxType,
+			wantPredicted: 21000 * 31,
+			wantMaxFee:    21000 * 31,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			helper, err := NewEIP1559TxHelperWithBackend(&fakeDryRunBackend{baseFee: big.NewInt(30)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gasParams := test.gasParams
+
+			result, err := helper.DryRunTransaction(context.Background(), signer, &to, chainID, &gasParams, value, nil)
+			if err != nil {
+				t.Fatalf("dry-run failed: %s", err)
+			}
+
+			if result.SignedTx.Type() != test.wantTxType {
+				t.Errorf("tx type %d, want %d", result.SignedTx.Type(), test.wantTxType)
+			}
+
+			if result.Nonce != 3 {
+				t.Errorf("nonce %d, want 3", result.Nonce)
+			}
+
+			if result.PredictedFee.Cmp(big.NewInt(test.wantPredicted)) != 0 {
+				t.Errorf("predicted fee %s, want %d", result.PredictedFee, test.wantPredicted)
+			}
+
+			if result.MaxFee.Cmp(big.NewInt(test.wantMaxFee)) != 0 {
+				t.Errorf("max fee %s, want %d", result.MaxFee, test.wantMaxFee)
+			}
+
+			wantMaxCost := new(big.Int).Add(big.NewInt(test.wantMaxFee), value)
+			if result.MaxCost.Cmp(wantMaxCost) != 0 {
+				t.Errorf("max cost %s, want %s", result.MaxCost, wantMaxCost)
+			}
+		})
+	}
+}
@@ -7,12 +7,12 @@ import (
 	"github.com/anxp/array-basics"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"log"
+	"github.com/ethereum/go-ethereum/rpc"
 	"math/big"
+	"time"
 )
 
 /*
@@ -32,18 +32,42 @@ How to calculate Gas:
 */
 
 type EIP1559TransactionHelper struct {
-	rpcUrl    string
-	ethClient *ethclient.Client
-	gasTipCap *big.Int
-
-	emulation   bool          // Emulation of sending instead of real sending
-	receiptMock types.Receipt // If emulation enabled, SendTransaction will always return this receipt mock
+	rpcUrl      string
+	registryKey string            // Key under which helper is stored in TxHelperRegistry (rpcUrl + configuration)
+	ethClient   *ethclient.Client // nil if helper was created with custom backend (see NewEIP1559TxHelperWithBackend)
+	backend     TxHelperBackend   // All node calls go through backend; for helpers created by NewEIP1559TxHelper it is ethClient
+	gasTipCap   *big.Int
+	feeStrategy FeeStrategy // Calculates gasTipCap for GetGasParameters, nil means fixed gasTipCap (see WithFeeStrategy)
+	txType      TxType      // Type of transactions GetGasParameters prepares parameters for (see WithTxType)
+
+	accessListOptimization bool // GetGasParameters attaches access list (eth_createAccessList) if it lowers gas (see WithAccessListOptimization)
+
+	baseFeeHeadroomBlocks *uint64 // Fee cap covers predicted base fee after this number of full blocks, nil means 2*baseFee (see WithBaseFeeHeadroom)
+	baseFeeElasticity     uint64  // EIP-1559 elasticity multiplier (see WithBaseFeeParams)
+	baseFeeDenominator    uint64  // EIP-1559 base fee change denominator (see WithBaseFeeParams)
+
+	nonceManager   *nonceManager  // Local nonce manager, nil if disabled (see WithNonceManager)
+	speedUpPolicy  *SpeedUpPolicy // Fee bumping for stuck transactions, nil if disabled (see WithSpeedUpPolicy)
+	revertDecoding bool           // Return decoded *RevertError along with receipt of reverted transaction (see WithRevertDecoding)
+	revertABIs     []abi.ABI      // ABIs to resolve custom errors from
+
+	preflightSimulation bool          // Simulate transaction (eth_call at pending block) before broadcasting (see WithPreflightSimulation)
+	defaultTimeout      time.Duration // Timeout applied by context-free methods (GetGasParameters, SendTransaction, ...), 0 means no timeout
+
+	emulation bool        // Emulation of sending instead of real sending
+	emulator  *TxEmulator // If emulation enabled, SendTransaction returns receipts from emulator (by default - receipt mock)
 }
 
+// Gas1559Params - gas parameters of transaction. Despite the name, it describes legacy and EIP-2930 transactions too:
+// for them GasPrice is set (GasTipCap and GasFeeCap are equal to it), see TxType.
 type Gas1559Params struct {
 	GasTipCap *big.Int // a.k.a. maxPriorityFeePerGas
 	GasFeeCap *big.Int // a.k.a. maxFeePerGas
 	Gas       uint64
+
+	TxType     TxType           // Type of transaction to build, TxTypeAuto means LegacyTx if GasPrice is set and DynamicFeeTx otherwise
+	GasPrice   *big.Int         // For legacy and access list transactions only
+	AccessList types.AccessList // Optional for DynamicFeeTx and AccessListTx, ignored for LegacyTx
 }
 
 // CreateEIP1559TxHelper
@@ -55,70 +79,260 @@ type Gas1559Params struct {
 //	total gas needed (gasAmountNeeded) can be too high and unacceptable for low banks (greater than transaction net value),
 //	and lead to error "insufficient funds for gas * price + value"
 //	For L2 networks gasTip = 100_000_000 is recommended
+//
+// CreateEIP1559TxHelper is a thin wrapper around NewEIP1559TxHelper, kept for backward compatibility.
+//
+// BREAKING CHANGE: previously CreateEIP1559TxHelper terminated the process (log.Fatal) if connection to RPC node failed.
+// Now it returns nil in this case, and error is reported via package logger (see SetLogger).
+// Callers MUST check result for nil, or better switch to NewEIP1559TxHelper, which returns the error itself.
 func CreateEIP1559TxHelper(rpcUrl string, gasTip int64, emulation bool, receiptMock types.Receipt) *EIP1559TransactionHelper {
+	txHelper, err := NewEIP1559TxHelper(rpcUrl, WithGasTip(gasTip), WithEmulation(emulation), WithReceiptMock(receiptMock))
+	if err != nil {
+		logf("CreateEIP1559TxHelper failed, nil helper returned: %s", err)
+		return nil
+	}
+
+	return txHelper
+}
+
+// NewEIP1559TxHelper creates (or takes from registry) helper for given rpcUrl, configured by options:
+// WithGasTip, WithEmulation, WithReceiptMock, WithDialTimeout, WithHTTPClient.
+//
+// Unlike CreateEIP1559TxHelper it returns error if connection to RPC node cannot be established.
+func NewEIP1559TxHelper(rpcUrl string, opts ...TxHelperOption) (*EIP1559TransactionHelper, error) {
+	config := newTxHelperConfig(opts...)
+	registryKey := config.registryKey(rpcUrl)
 
-	if txHelper := createTxHelperRegistry().getTxHelper(rpcUrl); txHelper != nil {
-		return txHelper
+	if txHelper := createTxHelperRegistry().getTxHelper(registryKey); txHelper != nil {
+		return txHelper, nil
 	}
 
-	ethClient, err := ethclient.Dial(rpcUrl)
+	ethClient, err := dialEthClient(rpcUrl, config)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	if gasTip <= 0 {
-		gasTip = 2_000_000_000
+	txHelper := newTxHelper(config, ethClient)
+	txHelper.rpcUrl = rpcUrl
+	txHelper.registryKey = registryKey
+
+	// Somebody could register the same configuration while we were dialing, in this case we use registered helper
+	if registered := createTxHelperRegistry().addTxHelperToRegistry(txHelper); registered != txHelper {
+		ethClient.Close()
+		return registered, nil
 	}
 
-	txHelper := &EIP1559TransactionHelper{
-		rpcUrl:      rpcUrl,
-		ethClient:   ethClient,
-		gasTipCap:   big.NewInt(gasTip),
-		emulation:   emulation,
-		receiptMock: receiptMock,
+	return txHelper, nil
+}
+
+// NewEIP1559TxHelperWithBackend creates helper working through given backend instead of dialing RPC node,
+// for example go-ethereum's simulated backend (ethclient/simulated) or custom fake.
+//
+// Helpers created this way are NOT stored in TxHelperRegistry, GetEthClient returns nil for them
+// (unless backend itself is *ethclient.Client). Options WithDialTimeout and WithHTTPClient are ignored.
+func NewEIP1559TxHelperWithBackend(backend TxHelperBackend, opts ...TxHelperOption) (*EIP1559TransactionHelper, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("backend must not be nil")
 	}
 
-	createTxHelperRegistry().addTxHelperToRegistry(txHelper)
+	config := newTxHelperConfig(opts...)
 
-	return txHelper
+	return newTxHelper(config, backend), nil
 }
 
+// newTxHelper creates helper from configuration, rpcUrl and registryKey are set by caller (if needed)
+func newTxHelper(config *txHelperConfig, backend TxHelperBackend) *EIP1559TransactionHelper {
+	ethClient, _ := backend.(*ethclient.Client)
+
+	return &EIP1559TransactionHelper{
+		ethClient:      ethClient,
+		backend:        backend,
+		gasTipCap:      big.NewInt(config.gasTip),
+		feeStrategy:    config.feeStrategy,
+		txType:         config.txType,
+		defaultTimeout: config.defaultTimeout,
+		nonceManager:   config.newNonceManager(),
+		speedUpPolicy:  config.speedUpPolicy,
+		revertDecoding: config.revertDecoding,
+		revertABIs:     config.revertABIs,
+
+		baseFeeHeadroomBlocks: config.baseFeeHeadroomBlocks,
+		baseFeeElasticity:     config.baseFeeElasticity,
+		baseFeeDenominator:    config.baseFeeDenominator,
+
+		accessListOptimization: config.accessListOptimization,
+
+		preflightSimulation: config.preflightSimulation,
+		emulation:           config.emulation,
+		emulator:            config.newEmulator(),
+	}
+}
+
+func dialEthClient(rpcUrl string, config *txHelperConfig) (*ethclient.Client, error) {
+	ctx := context.Background()
+
+	if config.dialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.dialTimeout)
+		defer cancel()
+	}
+
+	var dialOptions []rpc.ClientOption
+	if config.httpClient != nil {
+		dialOptions = append(dialOptions, rpc.WithHTTPClient(config.httpClient))
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, rpcUrl, dialOptions...)
+	if err != nil {
+		return nil, WrapExternalError(err, fmt.Sprintf("failed to connect to RPC node \"%s\"", rpcUrl))
+	}
+
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// defaultContext returns context used by context-free methods: background context, limited by defaultTimeout (if set)
+func (eipHelper *EIP1559TransactionHelper) defaultContext() (context.Context, context.CancelFunc) {
+	if eipHelper.defaultTimeout > 0 {
+		return context.WithTimeout(context.Background(), eipHelper.defaultTimeout)
+	}
+
+	return context.WithCancel(context.Background())
+}
+
+// GetGasParameters - same as GetGasParametersWithContext, but uses default context (see WithDefaultTimeout)
 func (eipHelper *EIP1559TransactionHelper) GetGasParameters(from common.Address, to *common.Address, value *big.Int, data []byte) (Gas1559Params, error) {
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.GetGasParametersWithContext(ctx, from, to, value, data)
+}
+
+func (eipHelper *EIP1559TransactionHelper) GetGasParametersWithContext(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte) (Gas1559Params, error) {
 	if eipHelper.emulation {
+		return eipHelper.emulatedGasParameters(), nil
+	}
+
+	gasParams, err := eipHelper.gasParametersFromNode(ctx, from, to, value, data)
+
+	if err != nil || !eipHelper.accessListOptimization {
+		return gasParams, err
+	}
+
+	return eipHelper.withAccessList(ctx, gasParams, from, to, value, data), nil
+}
+
+// emulatedGasParameters - in emulation mode fees are calculated from emulator's base fee, if it is not set - zeros are returned.
+// Fee strategy is not used in emulation mode (it may need node), fixed gasTipCap is applied.
+// For TxTypeLegacy and TxTypeAccessList (see WithTxType) calculated fee cap is used as gas price.
+func (eipHelper *EIP1559TransactionHelper) emulatedGasParameters() Gas1559Params {
+	baseFee := eipHelper.emulator.emulatedBaseFee()
+
+	if baseFee == nil {
 		return Gas1559Params{
 			GasTipCap: big.NewInt(0),
 			GasFeeCap: big.NewInt(0),
 			Gas:       0,
-		}, nil
+		}
+	}
+
+	if eipHelper.txType == TxTypeLegacy || eipHelper.txType == TxTypeAccessList {
+		return gasPriceParams(eipHelper.txType, eipHelper.emulatedGasFeeCap(baseFee), eipHelper.emulator.emulatedGasLimit())
+	}
+
+	return Gas1559Params{
+		GasTipCap: eipHelper.gasTipCap,
+		GasFeeCap: eipHelper.emulatedGasFeeCap(baseFee),
+		Gas:       eipHelper.emulator.emulatedGasLimit(),
+	}
+}
+
+// emulatedGasFeeCap - emulator has no header, its base fee is treated as base fee of the next block
+func (eipHelper *EIP1559TransactionHelper) emulatedGasFeeCap(baseFee *big.Int) *big.Int {
+	if eipHelper.baseFeeHeadroomBlocks == nil {
+		return gasFeeCap(baseFee, eipHelper.gasTipCap)
 	}
 
-	header, err := eipHelper.ethClient.HeaderByNumber(context.Background(), nil)
+	return eipHelper.gasFeeCapWithHeadroom(baseFee, eipHelper.gasTipCap)
+}
+
+// gasFeeCap calculates maxFeePerGas from base fee and tip
+func gasFeeCap(baseFee *big.Int, gasTipCap *big.Int) *big.Int {
+	// Doubling the Base Fee when calculating the Max Fee ensures that your transaction will remain marketable for six consecutive 100% full blocks.
+	gasFeeCap := big.NewInt(0)                                      // a.k.a. maxFeePerGas
+	gasFeeCap.Mul(baseFee, big.NewInt(2)).Add(gasFeeCap, gasTipCap) // Calculate the max fee per gas (2*baseFee + gasTipCap)
+
+	return gasFeeCap
+}
+
+// gasParametersFromNode calculates gas parameters from node data regardless of emulation mode.
+// For chains without EIP-1559 (no base fee in header) and for TxTypeLegacy / TxTypeAccessList gas price is taken from eth_gasPrice.
+func (eipHelper *EIP1559TransactionHelper) gasParametersFromNode(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte) (Gas1559Params, error) {
+	header, err := eipHelper.backend.HeaderByNumber(ctx, nil)
 
 	if err != nil {
 		return Gas1559Params{}, WrapExternalError(err, "failed to request last block header")
 	}
 
-	baseFee := header.BaseFee
+	txType, err := eipHelper.txTypeFor(header)
 
-	// Doubling the Base Fee when calculating the Max Fee ensures that your transaction will remain marketable for six consecutive 100% full blocks.
-	gasFeeCap := big.NewInt(0)                                                // a.k.a. maxFeePerGas
-	gasFeeCap.Mul(baseFee, big.NewInt(2)).Add(gasFeeCap, eipHelper.gasTipCap) // Calculate the max fee per gas (2*baseFee + gasTipCap)
+	if err != nil {
+		return Gas1559Params{}, err
+	}
+
+	if txType != TxTypeDynamicFee {
+		gasPrice, err := eipHelper.suggestGasPrice(ctx)
+
+		if err != nil {
+			return Gas1559Params{}, err
+		}
+
+		gasLimit, err := estimateGas(ctx, eipHelper.backend, from, to, value, data, eipHelper.revertABIs...)
 
-	gasLimit, err := estimateGas(eipHelper.ethClient, from, to, value, data)
+		if err != nil {
+			return Gas1559Params{}, err
+		}
+
+		return gasPriceParams(txType, gasPrice, gasLimit), nil
+	}
+
+	gasTipCap, err := eipHelper.suggestGasTipCap(ctx)
+
+	if err != nil {
+		return Gas1559Params{}, err
+	}
+
+	gasLimit, err := estimateGas(ctx, eipHelper.backend, from, to, value, data, eipHelper.revertABIs...)
 
 	if err != nil {
 		return Gas1559Params{}, err
 	}
 
 	return Gas1559Params{
-		GasTipCap: eipHelper.gasTipCap,
-		GasFeeCap: gasFeeCap,
+		TxType:    TxTypeDynamicFee,
+		GasTipCap: gasTipCap,
+		GasFeeCap: eipHelper.gasFeeCap(header, gasTipCap),
 		Gas:       gasLimit,
 	}, nil
 }
 
+// GetBaseFee - same as GetBaseFeeWithContext, but uses default context (see WithDefaultTimeout)
 func (eipHelper *EIP1559TransactionHelper) GetBaseFee() (*big.Int, error) {
-	header, err := eipHelper.ethClient.HeaderByNumber(context.Background(), nil)
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.GetBaseFeeWithContext(ctx)
+}
+
+// GetBaseFeeWithContext returns base fee of the latest block, 0 for chains without EIP-1559.
+// In emulation mode base fee set by TxEmulator.SetBaseFee is returned, if any.
+func (eipHelper *EIP1559TransactionHelper) GetBaseFeeWithContext(ctx context.Context) (*big.Int, error) {
+	if eipHelper.emulation {
+		if baseFee := eipHelper.emulator.emulatedBaseFee(); baseFee != nil {
+			return baseFee, nil
+		}
+	}
+
+	header, err := eipHelper.backend.HeaderByNumber(ctx, nil)
 
 	if err != nil {
 		return nil, WrapExternalError(err, "failed to request last block header")
@@ -126,9 +340,15 @@ func (eipHelper *EIP1559TransactionHelper) GetBaseFee() (*big.Int, error) {
 
 	baseFee := header.BaseFee
 
+	if baseFee == nil {
+		return big.NewInt(0), nil // Chain does not support EIP-1559
+	}
+
 	return baseFee, nil
 }
 
+// SendTransaction - same as SendTransactionWithContext, but uses default context (see WithDefaultTimeout).
+// Without default timeout it waits for transaction to be mined as long as needed.
 func (eipHelper *EIP1559TransactionHelper) SendTransaction(
 	privateKey *ecdsa.PrivateKey,
 	to *common.Address,
@@ -137,50 +357,152 @@ func (eipHelper *EIP1559TransactionHelper) SendTransaction(
 	value *big.Int,
 	data []byte,
 ) (receipt *types.Receipt, err error) {
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.SendTransactionWithContext(ctx, privateKey, to, chainID, gasParams, value, data)
+}
+
+// SendTransactionWithContext signs transaction, sends it and waits for it to be mined.
+// Waiting is interrupted when ctx is done (cancelled or deadline exceeded).
+func (eipHelper *EIP1559TransactionHelper) SendTransactionWithContext(
+	ctx context.Context,
+	privateKey *ecdsa.PrivateKey,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (receipt *types.Receipt, err error) {
 
 	if eipHelper.emulation {
-		return &eipHelper.receiptMock, nil
+		from := common.Address{} // Private key was never required in emulation mode
+		if privateKey != nil {
+			from, _ = GetPublicAddressFromPrivateKey(privateKey)
+		}
+
+		return eipHelper.emulateSend(from, to, chainID, gasParams, value, data)
 	}
 
-	from, err := GetPublicAddressFromPrivateKey(privateKey)
+	signer, err := NewPrivateKeySigner(privateKey)
 	if err != nil {
 		return nil, err
 	}
 
-	nonce, err := eipHelper.ethClient.PendingNonceAt(context.Background(), from)
+	return eipHelper.SendTransactionWithSigner(ctx, signer, to, chainID, gasParams, value, data)
+}
+
+// SendTransactionWithSigner - same as SendTransactionWithContext, but transaction is signed by given Signer
+// (keystore, remote signer, KMS, ...) instead of in-memory private key.
+func (eipHelper *EIP1559TransactionHelper) SendTransactionWithSigner(
+	ctx context.Context,
+	signer Signer,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (*types.Receipt, error) {
+
+	receipt, _, err := eipHelper.SendTransactionWithSimulationResult(ctx, signer, to, chainID, gasParams, value, data)
+
+	return receipt, err
+}
+
+// SendTransactionWithSimulationResult - same as SendTransactionWithSigner, but also returns data returned by pre-flight
+// simulation (see WithPreflightSimulation), e.g. return value of called contract method. simulationResult is nil
+// if pre-flight simulation is disabled, and in emulation mode.
+func (eipHelper *EIP1559TransactionHelper) SendTransactionWithSimulationResult(
+	ctx context.Context,
+	signer Signer,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (receipt *types.Receipt, simulationResult []byte, err error) {
+
+	if eipHelper.emulation {
+		receipt, err = eipHelper.emulateSend(signer.Address(), to, chainID, gasParams, value, data)
+		return receipt, nil, err
+	}
+
+	signedTx, simulationResult, err := eipHelper.signAndSend(ctx, signer, to, chainID, gasParams, value, data)
 	if err != nil {
-		return nil, WrapExternalError(err, "failed to get nonce")
+		return nil, nil, err
 	}
 
-	tx := types.NewTx(&types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     nonce,
-		GasTipCap: gasParams.GasTipCap,
-		GasFeeCap: gasParams.GasFeeCap,
-		Gas:       gasParams.Gas,
-		To:        to,
-		Value:     value,
-		Data:      data,
-	})
+	if eipHelper.speedUpPolicy != nil {
+		receipt, err = eipHelper.waitMinedWithSpeedUp(ctx, signer, signedTx)
+	} else {
+		receipt, err = waitMined(ctx, eipHelper.backend, signedTx.Hash())
+		if err != nil {
+			// Transaction is already broadcast, so caller must know its hash to track it further
+			err = &ExternalErrorWrapper{
+				OriginalError:     err,
+				OurMessage:        "transaction probably has not been mined (timeout?)",
+				AdditionalContext: fmt.Sprintf("tx hash: %s, nonce: %d", signedTx.Hash().Hex(), signedTx.Nonce()),
+			}
+		}
+	}
 
-	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign transaction: %s", err) // sign is not an external call, isn't it? so we don't use wrapper for external error
+		return nil, simulationResult, err
 	}
 
-	if err = eipHelper.ethClient.SendTransaction(context.Background(), signedTx); err != nil {
-		// Possible errors:
-		// 1. insufficient funds for gas * price + value (https://ethereum.stackexchange.com/questions/78072/get-an-error-insufficient-funds-for-gas-price-value)
-		// 2. replacement transaction underpriced
-		return nil, WrapExternalError(err, "failed to send transaction")
+	// Even if one of replacements was mined (speed-up), it has the same from/to/value/data/gas, so it can be replayed as signedTx
+	if eipHelper.revertDecoding && receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, simulationResult, eipHelper.revertedReceiptError(ctx, signedTx, receipt)
+	}
+
+	return receipt, simulationResult, nil
+}
+
+// signAndSend builds transaction (type is given by gasParams, see Gas1559Params.TxType), signs it and broadcasts it, without waiting for it to be mined.
+// If pre-flight simulation is enabled (see WithPreflightSimulation), transaction is simulated first, and its return data is returned.
+func (eipHelper *EIP1559TransactionHelper) signAndSend(
+	ctx context.Context,
+	signer Signer,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (signedTx *types.Transaction, simulationResult []byte, err error) {
+	from := signer.Address()
+
+	// Simulation goes before nonce acquiring, so refused transaction does not leave a gap
+	if eipHelper.preflightSimulation {
+		simulationResult, err = eipHelper.SimulateTransaction(ctx, from, to, value, data, gasParams.Gas)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	nonce, err := eipHelper.acquireNonce(ctx, from)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	receipt, err = bind.WaitMined(context.Background(), eipHelper.ethClient, signedTx)
+	tx := buildTransaction(chainID, nonce, gasParams, to, value, data)
+
+	signedTx, err = signer.SignTx(tx, chainID)
 	if err != nil {
-		return nil, WrapExternalError(err, "transaction probably has not been mined (timeout?)")
+		eipHelper.releaseNonce(from, nonce, false)
+		return nil, nil, fmt.Errorf("failed to sign transaction: %s", err) // sign is not an external call, isn't it? so we don't use wrapper for external error
 	}
 
-	return receipt, nil
+	err = eipHelper.backend.SendTransaction(ctx, signedTx)
+	eipHelper.releaseNonce(from, nonce, err == nil)
+
+	if err != nil {
+		// Possible errors:
+		// 1. insufficient funds for gas * price + value (https://ethereum.stackexchange.com/questions/78072/get-an-error-insufficient-funds-for-gas-price-value)
+		// 2. replacement transaction underpriced
+		return nil, nil, WrapExternalError(err, "failed to send transaction")
+	}
+
+	return signedTx, simulationResult, nil
 }
 
 // FilterTransactionLog filters INDEXED (only topics) transaction logs by applying ethereum.FilterQuery filter
@@ -196,7 +518,7 @@ func (eipHelper *EIP1559TransactionHelper) SendTransaction(
 // Topics - restricts matches to particular event topics, an empty element slice matches any topic, also see -> ethereum.FilterQuery
 func (eipHelper *EIP1559TransactionHelper) FilterTransactionLog(txReceipt *types.Receipt, txLogs []*types.Log, filter ethereum.FilterQuery) ([]types.Log, error) {
 	if txReceipt != nil && txLogs != nil {
-		panic("receipt OR logs should be provided, but not both!")
+		return nil, fmt.Errorf("receipt OR logs should be provided, but not both")
 	}
 
 	if txReceipt == nil && txLogs == nil {
@@ -275,17 +597,35 @@ func (eipHelper *EIP1559TransactionHelper) FilterTransactionLog(txReceipt *types
 //
 // [READONLY] This is NOT-state-changing call
 func (eipHelper *EIP1559TransactionHelper) ContractFunctionCall(contractAddress *common.Address, contractABI abi.ABI, blockNumber *big.Int, methodName string, args ...interface{}) ([]interface{}, error) {
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.ContractFunctionCallWithContext(ctx, contractAddress, contractABI, blockNumber, methodName, args...)
+}
+
+// ContractFunctionCallWithContext - same as ContractFunctionCall, but with caller's context.
+// In emulation mode response mocked by TxEmulator.MockCall is returned, if any.
+//
+// [READONLY] This is NOT-state-changing call
+func (eipHelper *EIP1559TransactionHelper) ContractFunctionCallWithContext(ctx context.Context, contractAddress *common.Address, contractABI abi.ABI, blockNumber *big.Int, methodName string, args ...interface{}) ([]interface{}, error) {
 	request, err := contractABI.Pack(methodName, args...)
 	if err != nil {
 		return nil, WrapExternalError(nil, err.Error()) // original error == nil  because we did not external request, all errors are local!
 	}
 
+	if eipHelper.emulation {
+		// Arguments are packed above anyway, to check them
+		if results, mocked, err := eipHelper.emulator.emulateCall(contractAddress, contractABI, methodName); mocked {
+			return results, err
+		}
+	}
+
 	msg := ethereum.CallMsg{
 		To:   contractAddress,
 		Data: request,
 	}
 
-	result, err := eipHelper.ethClient.CallContract(context.Background(), msg, blockNumber)
+	result, err := eipHelper.backend.CallContract(ctx, msg, blockNumber)
 	if err != nil {
 		return nil, WrapExternalError(err, fmt.Sprintf("failed to call function \"%s\" at contract \"%s\"", methodName, contractAddress))
 	}
@@ -306,8 +646,24 @@ func (eipHelper *EIP1559TransactionHelper) ContractFunctionCallNoArguments(contr
 	return eipHelper.ContractFunctionCall(contractAddress, contractABI, blockNumber, methodName)
 }
 
+// GetLatestBlockNumber - same as GetLatestBlockNumberWithContext, but uses default context (see WithDefaultTimeout)
 func (eipHelper *EIP1559TransactionHelper) GetLatestBlockNumber() (*big.Int, error) {
-	blockNumber, err := eipHelper.ethClient.BlockNumber(context.Background())
+	ctx, cancel := eipHelper.defaultContext()
+	defer cancel()
+
+	return eipHelper.GetLatestBlockNumberWithContext(ctx)
+}
+
+// GetLatestBlockNumberWithContext returns number of the latest block.
+// In emulation mode block number set by TxEmulator.SetBlockNumber is returned, if any.
+func (eipHelper *EIP1559TransactionHelper) GetLatestBlockNumberWithContext(ctx context.Context) (*big.Int, error) {
+	if eipHelper.emulation {
+		if blockNumber, ok := eipHelper.emulator.emulatedBlockNumber(); ok {
+			return big.NewInt(0).SetUint64(blockNumber), nil
+		}
+	}
+
+	blockNumber, err := eipHelper.backend.BlockNumber(ctx)
 	if err != nil {
 		return nil, WrapExternalError(err, "failed to get latest block number")
 	}
@@ -315,10 +671,29 @@ func (eipHelper *EIP1559TransactionHelper) GetLatestBlockNumber() (*big.Int, err
 	return big.NewInt(0).SetUint64(blockNumber), nil
 }
 
+// GetEthClient returns underlying *ethclient.Client, or nil if helper works through custom backend
 func (eipHelper *EIP1559TransactionHelper) GetEthClient() *ethclient.Client {
 	return eipHelper.ethClient
 }
 
+// Close removes helper from TxHelperRegistry and closes underlying RPC connection (custom backends are not closed), helper must not be used after that
+func (eipHelper *EIP1559TransactionHelper) Close() {
+	createTxHelperRegistry().removeIfRegistered(eipHelper)
+	eipHelper.closeEthClient()
+}
+
+func (eipHelper *EIP1559TransactionHelper) closeEthClient() {
+	// Only connection dialed by helper itself (rpcUrl is set) is closed, client passed by caller as backend is caller's business
+	if eipHelper.ethClient != nil && eipHelper.rpcUrl != "" {
+		eipHelper.ethClient.Close()
+	}
+}
+
+// GetBackend returns backend all node calls go through
+func (eipHelper *EIP1559TransactionHelper) GetBackend() TxHelperBackend {
+	return eipHelper.backend
+}
+
 func (eipHelper *EIP1559TransactionHelper) GetRpcUrl() string {
 	return eipHelper.rpcUrl
 }
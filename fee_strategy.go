@@ -0,0 +1,198 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"math/big"
+	"sort"
+)
+
+// FeeUrgency - how fast transaction should be mined, strategies translate it to higher or lower tip
+type FeeUrgency int
+
+const (
+	FeeUrgencyNormal FeeUrgency = iota // Default (zero value)
+	FeeUrgencySlow
+	FeeUrgencyFast
+)
+
+func (urgency FeeUrgency) String() string {
+	switch urgency {
+	case FeeUrgencyNormal:
+		return "normal"
+	case FeeUrgencySlow:
+		return "slow"
+	case FeeUrgencyFast:
+		return "fast"
+	default:
+		return fmt.Sprintf("FeeUrgency(%d)", int(urgency))
+	}
+}
+
+// FeeStrategy calculates gasTipCap (a.k.a. maxPriorityFeePerGas) used by GetGasParameters (see WithFeeStrategy).
+// Strategies shipped with the package: FixedTipStrategy, FeeHistoryStrategy, MaxPriorityFeeStrategy.
+type FeeStrategy interface {
+	GasTipCap(ctx context.Context, backend TxHelperBackend) (*big.Int, error)
+}
+
+// FixedTipStrategy always returns the same tip for given urgency (tip given by WithGasTip works this way).
+// Slow and Fast are optional, if nil - Normal is used.
+type FixedTipStrategy struct {
+	Slow    *big.Int
+	Normal  *big.Int
+	Fast    *big.Int
+	Urgency FeeUrgency
+	MinTip  *big.Int // Optional, nil means no limit
+	MaxTip  *big.Int // Optional, nil means no limit
+}
+
+func (strategy FixedTipStrategy) GasTipCap(_ context.Context, _ TxHelperBackend) (*big.Int, error) {
+	tip := strategy.Normal
+
+	switch {
+	case strategy.Urgency == FeeUrgencySlow && strategy.Slow != nil:
+		tip = strategy.Slow
+	case strategy.Urgency == FeeUrgencyFast && strategy.Fast != nil:
+		tip = strategy.Fast
+	}
+
+	if tip == nil {
+		return nil, fmt.Errorf("fixed tip strategy: tip for urgency \"%s\" is not set", strategy.Urgency)
+	}
+
+	return clampTip(new(big.Int).Set(tip), strategy.MinTip, strategy.MaxTip), nil
+}
+
+const defaultFeeHistoryBlocks uint64 = 10
+
+// defaultFeeHistoryPercentiles - eth_feeHistory reward percentile per urgency
+var defaultFeeHistoryPercentiles = map[FeeUrgency]float64{
+	FeeUrgencySlow:   10,
+	FeeUrgencyNormal: 50,
+	FeeUrgencyFast:   90,
+}
+
+// FeeHistoryStrategy takes tip from eth_feeHistory: reward percentile of transactions in last Blocks blocks
+// (10th for slow, 50th for normal, 90th for fast), median across non-empty blocks. Backend must implement ethereum.FeeHistoryReader.
+type FeeHistoryStrategy struct {
+	Blocks     uint64  // How many last blocks to analyze, 0 means 10
+	Percentile float64 // Optional, overrides percentile chosen by Urgency (0-100)
+	Urgency    FeeUrgency
+	MinTip     *big.Int // Optional, nil means no limit. Also returned when all analyzed blocks are empty.
+	MaxTip     *big.Int // Optional, nil means no limit
+}
+
+func (strategy FeeHistoryStrategy) GasTipCap(ctx context.Context, backend TxHelperBackend) (*big.Int, error) {
+	feeHistoryReader, ok := backend.(ethereum.FeeHistoryReader)
+	if !ok {
+		return nil, fmt.Errorf("fee history strategy: backend does not support eth_feeHistory")
+	}
+
+	blocks := strategy.Blocks
+	if blocks == 0 {
+		blocks = defaultFeeHistoryBlocks
+	}
+
+	percentile := strategy.Percentile
+	if percentile <= 0 {
+		percentile, ok = defaultFeeHistoryPercentiles[strategy.Urgency]
+		if !ok {
+			return nil, fmt.Errorf("fee history strategy: unknown urgency %s", strategy.Urgency)
+		}
+	}
+
+	feeHistory, err := feeHistoryReader.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, WrapExternalError(err, "failed to request fee history")
+	}
+
+	rewards := make([]*big.Int, 0, len(feeHistory.Reward))
+	for i, blockRewards := range feeHistory.Reward {
+		if len(blockRewards) == 0 || blockRewards[0] == nil {
+			continue
+		}
+
+		// Empty blocks report zero reward, they say nothing about market
+		if i < len(feeHistory.GasUsedRatio) && feeHistory.GasUsedRatio[i] == 0 {
+			continue
+		}
+
+		rewards = append(rewards, blockRewards[0])
+	}
+
+	if len(rewards) == 0 {
+		if strategy.MinTip != nil {
+			return new(big.Int).Set(strategy.MinTip), nil
+		}
+
+		return nil, fmt.Errorf("fee history strategy: no transactions in last %d blocks, set MinTip to use as fallback", blocks)
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+
+	tip := new(big.Int).Set(rewards[len(rewards)/2])
+
+	return clampTip(tip, strategy.MinTip, strategy.MaxTip), nil
+}
+
+// maxPriorityFeeUrgencyPercent - how node's suggestion is scaled for given urgency
+var maxPriorityFeeUrgencyPercent = map[FeeUrgency]int64{
+	FeeUrgencySlow:   80,
+	FeeUrgencyNormal: 100,
+	FeeUrgencyFast:   125,
+}
+
+// MaxPriorityFeeStrategy takes tip suggested by node (eth_maxPriorityFeePerGas), scaled by urgency:
+// 80% for slow, 100% for normal, 125% for fast. Backend must implement ethereum.GasPricer1559.
+type MaxPriorityFeeStrategy struct {
+	Urgency FeeUrgency
+	MinTip  *big.Int // Optional, nil means no limit
+	MaxTip  *big.Int // Optional, nil means no limit
+}
+
+func (strategy MaxPriorityFeeStrategy) GasTipCap(ctx context.Context, backend TxHelperBackend) (*big.Int, error) {
+	gasPricer, ok := backend.(ethereum.GasPricer1559)
+	if !ok {
+		return nil, fmt.Errorf("max priority fee strategy: backend does not support eth_maxPriorityFeePerGas")
+	}
+
+	percent, ok := maxPriorityFeeUrgencyPercent[strategy.Urgency]
+	if !ok {
+		return nil, fmt.Errorf("max priority fee strategy: unknown urgency %s", strategy.Urgency)
+	}
+
+	suggestedTip, err := gasPricer.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, WrapExternalError(err, "failed to request max priority fee")
+	}
+
+	tip := new(big.Int).Mul(suggestedTip, big.NewInt(percent))
+	tip.Div(tip, big.NewInt(100))
+
+	return clampTip(tip, strategy.MinTip, strategy.MaxTip), nil
+}
+
+// clampTip limits tip by optional min and max (nil means no limit)
+func clampTip(tip *big.Int, minTip *big.Int, maxTip *big.Int) *big.Int {
+	if minTip != nil && tip.Cmp(minTip) < 0 {
+		return new(big.Int).Set(minTip)
+	}
+
+	if maxTip != nil && tip.Cmp(maxTip) > 0 {
+		return new(big.Int).Set(maxTip)
+	}
+
+	return tip
+}
+
+// suggestGasTipCap returns tip from fee strategy, or fixed tip (see WithGasTip) if strategy is not set
+func (eipHelper *EIP1559TransactionHelper) suggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if eipHelper.feeStrategy == nil {
+		return eipHelper.gasTipCap, nil
+	}
+
+	return eipHelper.feeStrategy.GasTipCap(ctx, eipHelper.backend)
+}
@@ -0,0 +1,253 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// fakeFeeBlock - block in fake fee history: reward per percentile, 0 gas used ratio means empty block
+type fakeFeeBlock struct {
+	gasUsedRatio float64
+	rewards      map[float64]int64
+}
+
+// fakeFeeBackend - node answering eth_feeHistory and eth_maxPriorityFeePerGas only
+type fakeFeeBackend struct {
+	TxHelperBackend // Not used by fee strategies, calls of other methods panic
+
+	blocks       []fakeFeeBlock
+	suggestedTip int64
+
+	requestedBlocks      uint64
+	requestedPercentiles []float64
+}
+
+func (backend *fakeFeeBackend) FeeHistory(_ context.Context, blockCount uint64, _ *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	backend.requestedBlocks = blockCount
+	backend.requestedPercentiles = rewardPercentiles
+
+	feeHistory := &ethereum.FeeHistory{OldestBlock: big.NewInt(1)}
+
+	for _, block := range backend.blocks {
+		rewards := make([]*big.Int, 0, len(rewardPercentiles))
+		for _, percentile := range rewardPercentiles {
+			rewards = append(rewards, big.NewInt(block.rewards[percentile]))
+		}
+
+		feeHistory.Reward = append(feeHistory.Reward, rewards)
+		feeHistory.GasUsedRatio = append(feeHistory.GasUsedRatio, block.gasUsedRatio)
+	}
+
+	return feeHistory, nil
+}
+
+func (backend *fakeFeeBackend) SuggestGasTipCap(_ context.Context) (*big.Int, error) {
+	return big.NewInt(backend.suggestedTip), nil
+}
+
+func TestFeeHistoryStrategy(t *testing.T) {
+	rewards := func(slow int64, normal int64, fast int64) map[float64]int64 {
+		return map[float64]int64{10: slow, 50: normal, 90: fast, 75: normal + 1}
+	}
+
+	busyBlocks := []fakeFeeBlock{
+		{gasUsedRatio: 0.5, rewards: rewards(1, 10, 100)},
+		{gasUsedRatio: 0, rewards: rewards(0, 0, 0)}, // Empty block, skipped
+		{gasUsedRatio: 0.9, rewards: rewards(3, 30, 300)},
+		{gasUsedRatio: 0.3, rewards: rewards(2, 20, 200)},
+		{gasUsedRatio: 0, rewards: rewards(0, 0, 0)},
+	}
+
+	emptyBlocks := []fakeFeeBlock{
+		{gasUsedRatio: 0, rewards: rewards(0, 0, 0)},
+		{gasUsedRatio: 0, rewards: rewards(0, 0, 0)},
+	}
+
+	tests := []struct {
+		name           string
+		blocks         []fakeFeeBlock
+		strategy       FeeHistoryStrategy
+		wantErr        bool
+		wantTip        int64
+		wantBlocks     uint64
+		wantPercentile float64
+	}{
+		{
+			name:           "normal urgency, median of non-empty blocks",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{},
+			wantTip:        20,
+			wantBlocks:     10,
+			wantPercentile: 50,
+		},
+		{
+			name:           "slow urgency",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{Urgency: FeeUrgencySlow, Blocks: 5},
+			wantTip:        2,
+			wantBlocks:     5,
+			wantPercentile: 10,
+		},
+		{
+			name:           "fast urgency",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{Urgency: FeeUrgencyFast},
+			wantTip:        200,
+			wantBlocks:     10,
+			wantPercentile: 90,
+		},
+		{
+			name:           "percentile overrides urgency",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{Urgency: FeeUrgencyFast, Percentile: 75},
+			wantTip:        21,
+			wantBlocks:     10,
+			wantPercentile: 75,
+		},
+		{
+			name:           "min tip clamp",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{MinTip: big.NewInt(25)},
+			wantTip:        25,
+			wantBlocks:     10,
+			wantPercentile: 50,
+		},
+		{
+			name:           "max tip clamp",
+			blocks:         busyBlocks,
+			strategy:       FeeHistoryStrategy{Urgency: FeeUrgencyFast, MaxTip: big.NewInt(150)},
+			wantTip:        150,
+			wantBlocks:     10,
+			wantPercentile: 90,
+		},
+		{
+			name:           "all blocks empty, min tip fallback",
+			blocks:         emptyBlocks,
+			strategy:       FeeHistoryStrategy{MinTip: big.NewInt(7)},
+			wantTip:        7,
+			wantBlocks:     10,
+			wantPercentile: 50,
+		},
+		{
+			name:     "all blocks empty, no min tip",
+			blocks:   emptyBlocks,
+			strategy: FeeHistoryStrategy{},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown urgency",
+			blocks:   busyBlocks,
+			strategy: FeeHistoryStrategy{Urgency: FeeUrgency(42)},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &fakeFeeBackend{blocks: test.blocks}
+
+			tip, err := test.strategy.GasTipCap(context.Background(), backend)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got tip %s", tip)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tip.Cmp(big.NewInt(test.wantTip)) != 0 {
+				t.Errorf("tip %s, want %d", tip, test.wantTip)
+			}
+
+			if backend.requestedBlocks != test.wantBlocks {
+				t.Errorf("requested %d blocks, want %d", backend.requestedBlocks, test.wantBlocks)
+			}
+
+			if len(backend.requestedPercentiles) != 1 || backend.requestedPercentiles[0] != test.wantPercentile {
+				t.Errorf("requested percentiles %v, want [%v]", backend.requestedPercentiles, test.wantPercentile)
+			}
+		})
+	}
+}
+
+func TestFeeHistoryStrategyUnsupportedBackend(t *testing.T) {
+	if _, err := (FeeHistoryStrategy{}).GasTipCap(context.Background(), &fakeNonceBackend{}); err == nil {
+		t.Fatal("expected error for backend without eth_feeHistory")
+	}
+}
+
+func TestMaxPriorityFeeStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy MaxPriorityFeeStrategy
+		wantErr  bool
+		wantTip  int64
+	}{
+		{name: "normal", strategy: MaxPriorityFeeStrategy{}, wantTip: 1000},
+		{name: "slow", strategy: MaxPriorityFeeStrategy{Urgency: FeeUrgencySlow}, wantTip: 800},
+		{name: "fast", strategy: MaxPriorityFeeStrategy{Urgency: FeeUrgencyFast}, wantTip: 1250},
+		{name: "min tip clamp", strategy: MaxPriorityFeeStrategy{Urgency: FeeUrgencySlow, MinTip: big.NewInt(900)}, wantTip: 900},
+		{name: "max tip clamp", strategy: MaxPriorityFeeStrategy{Urgency: FeeUrgencyFast, MaxTip: big.NewInt(1100)}, wantTip: 1100},
+		{name: "unknown urgency", strategy: MaxPriorityFeeStrategy{Urgency: FeeUrgency(42)}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tip, err := test.strategy.GasTipCap(context.Background(), &fakeFeeBackend{suggestedTip: 1000})
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got tip %s", tip)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tip.Cmp(big.NewInt(test.wantTip)) != 0 {
+				t.Errorf("tip %s, want %d", tip, test.wantTip)
+			}
+		})
+	}
+}
+
+func TestClampTip(t *testing.T) {
+	tests := []struct {
+		name    string
+		tip     int64
+		minTip  *big.Int
+		maxTip  *big.Int
+		wantTip int64
+	}{
+		{name: "no limits", tip: 5, wantTip: 5},
+		{name: "below min", tip: 5, minTip: big.NewInt(10), wantTip: 10},
+		{name: "above max", tip: 50, maxTip: big.NewInt(20), wantTip: 20},
+		{name: "within limits", tip: 15, minTip: big.NewInt(10), maxTip: big.NewInt(20), wantTip: 15},
+		{name: "equal to min", tip: 10, minTip: big.NewInt(10), maxTip: big.NewInt(20), wantTip: 10},
+		{name: "equal to max", tip: 20, minTip: big.NewInt(10), maxTip: big.NewInt(20), wantTip: 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tip := clampTip(big.NewInt(test.tip), test.minTip, test.maxTip)
+
+			if tip.Cmp(big.NewInt(test.wantTip)) != 0 {
+				t.Fatalf("tip %s, want %d", tip, test.wantTip)
+			}
+
+			// Limits are copied, never shared with returned tip
+			if (test.minTip != nil && tip == test.minTip) || (test.maxTip != nil && tip == test.maxTip) {
+				t.Fatal("returned tip is the limit itself")
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// DefaultDerivationPath - BIP-44 path of the first Ethereum account (m/44'/60'/0'/0/0), used by MetaMask, Ledger, etc.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// LoadSignerFromKeystoreJSON decrypts V3 keystore JSON (as created by geth, MetaMask export, etc.) with passphrase
+func LoadSignerFromKeystoreJSON(keyJSON []byte, passphrase string) (*PrivateKeySigner, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %s", err)
+	}
+
+	return NewPrivateKeySigner(key.PrivateKey)
+}
+
+// LoadSignerFromKeystoreFile reads V3 keystore file and decrypts it with passphrase
+func LoadSignerFromKeystoreFile(path string, passphrase string) (*PrivateKeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %s", err)
+	}
+
+	return LoadSignerFromKeystoreJSON(keyJSON, passphrase)
+}
+
+// LoadSignerFromHexEnv loads hex encoded private key (with or without 0x prefix) from environment variable
+func LoadSignerFromHexEnv(envName string) (*PrivateKeySigner, error) {
+	hexKey, ok := os.LookupEnv(envName)
+	if !ok || hexKey == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", envName)
+	}
+
+	hexKeyBytes := []byte(hexKey) // String itself can't be zeroed, but at least our copy is
+	defer zeroBytes(hexKeyBytes)
+
+	signer, err := signerFromHex(hexKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s: %s", envName, err)
+	}
+
+	return signer, nil
+}
+
+// LoadSignerFromHexFile loads hex encoded private key (with or without 0x prefix, surrounding whitespace is ignored) from file
+func LoadSignerFromHexFile(path string) (*PrivateKeySigner, error) {
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %s", err)
+	}
+
+	defer zeroBytes(fileContent)
+
+	signer, err := signerFromHex(fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %s", path, err)
+	}
+
+	return signer, nil
+}
+
+// LoadSignerFromMnemonic derives private key from BIP-39 mnemonic (with optional BIP-39 passphrase, "" if none)
+// by BIP-44 derivation path, e.g. DefaultDerivationPath
+func LoadSignerFromMnemonic(mnemonic string, passphrase string, derivationPath string) (*PrivateKeySigner, error) {
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path \"%s\": %s", derivationPath, err)
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %s", err)
+	}
+
+	defer zeroBytes(seed)
+
+	privateKey, err := deriveBIP32Key(seed, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivateKeySigner(privateKey)
+}
+
+// Wipe zeroes private key in memory, signer must not be used after that
+func (s *PrivateKeySigner) Wipe() {
+	zeroPrivateKey(s.privateKey)
+}
+
+func signerFromHex(hexKey []byte) (*PrivateKeySigner, error) {
+	hexKey = bytes.TrimSpace(hexKey)
+	hexKey = bytes.TrimPrefix(bytes.TrimPrefix(hexKey, []byte("0x")), []byte("0X"))
+
+	rawKey := make([]byte, hex.DecodedLen(len(hexKey)))
+	defer zeroBytes(rawKey)
+
+	if _, err := hex.Decode(rawKey, hexKey); err != nil {
+		return nil, fmt.Errorf("invalid hex private key")
+	}
+
+	privateKey, err := crypto.ToECDSA(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err)
+	}
+
+	return NewPrivateKeySigner(privateKey)
+}
+
+// deriveBIP32Key derives private key from seed by BIP-32 path (hardened and normal derivation)
+func deriveBIP32Key(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	masterMac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	masterMac.Write(seed)
+	intermediate := masterMac.Sum(nil)
+	defer zeroBytes(intermediate)
+
+	keyBytes, chainCode := intermediate[:32], intermediate[32:]
+
+	privateKey, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key derived from seed: %s", err)
+	}
+
+	for _, index := range path {
+		data := make([]byte, 0, 37)
+
+		if index >= 0x80000000 { // Hardened: 0x00 || private key || index
+			data = append(data, 0x00)
+			data = append(data, crypto.FromECDSA(privateKey)...)
+		} else { // Normal: compressed public key || index
+			data = append(data, crypto.CompressPubkey(&privateKey.PublicKey)...)
+		}
+
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		childMac := hmac.New(sha512.New, chainCode)
+		childMac.Write(data)
+		childIntermediate := childMac.Sum(nil)
+		zeroBytes(data)
+
+		tweak := new(big.Int).SetBytes(childIntermediate[:32])
+		if tweak.Cmp(secp256k1N) >= 0 {
+			zeroBytes(childIntermediate)
+			zeroPrivateKey(privateKey)
+			return nil, fmt.Errorf("invalid child key at index %d, use another derivation path", index)
+		}
+
+		childKey := tweak.Add(tweak, privateKey.D)
+		childKey.Mod(childKey, secp256k1N)
+
+		childKeyBytes := make([]byte, 32)
+		childKey.FillBytes(childKeyBytes)
+		zeroBigInt(childKey)
+		zeroPrivateKey(privateKey)
+
+		privateKey, err = crypto.ToECDSA(childKeyBytes)
+		zeroBytes(childKeyBytes)
+		if err != nil {
+			zeroBytes(childIntermediate)
+			return nil, fmt.Errorf("invalid child key at index %d: %s", index, err)
+		}
+
+		copy(chainCode, childIntermediate[32:])
+		zeroBytes(childIntermediate)
+	}
+
+	return privateKey, nil
+}
+
+func zeroBytes(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
+
+func zeroBigInt(value *big.Int) {
+	words := value.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+
+	value.SetInt64(0)
+}
+
+func zeroPrivateKey(privateKey *ecdsa.PrivateKey) {
+	if privateKey != nil && privateKey.D != nil {
+		zeroBigInt(privateKey.D)
+	}
+}
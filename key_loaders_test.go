@@ -0,0 +1,213 @@
+package goeth_tx_helper
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Well known development mnemonic (Hardhat, Anvil), its accounts are public
+const testMnemonic = "test test test test test test test test test test test junk"
+
+const testHardhatKey0 = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+var testHardhatAddress0 = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+func TestLoadSignerFromMnemonic(t *testing.T) {
+	tests := []struct {
+		name           string
+		mnemonic       string
+		derivationPath string
+		wantAddress    common.Address
+	}{
+		{
+			name:           "index 0",
+			mnemonic:       testMnemonic,
+			derivationPath: DefaultDerivationPath,
+			wantAddress:    testHardhatAddress0,
+		},
+		{
+			name:           "index 1",
+			mnemonic:       testMnemonic,
+			derivationPath: "m/44'/60'/0'/0/1",
+			wantAddress:    common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		},
+		{
+			name:           "extra whitespace in mnemonic",
+			mnemonic:       "  test test test test test test\ttest test test test test   junk\n",
+			derivationPath: DefaultDerivationPath,
+			wantAddress:    testHardhatAddress0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := LoadSignerFromMnemonic(test.mnemonic, "", test.derivationPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if signer.Address() != test.wantAddress {
+				t.Fatalf("address %s, want %s", signer.Address().Hex(), test.wantAddress.Hex())
+			}
+		})
+	}
+}
+
+func TestLoadSignerFromMnemonicErrors(t *testing.T) {
+	if _, err := LoadSignerFromMnemonic("test test test test test test test test test test test test", "", DefaultDerivationPath); err == nil {
+		t.Fatal("expected error for mnemonic with wrong checksum")
+	}
+
+	if _, err := LoadSignerFromMnemonic(testMnemonic, "", "m/44'/60'/x"); err == nil {
+		t.Fatal("expected error for invalid derivation path")
+	}
+}
+
+// BIP-32 test vector 1 (https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vector-1),
+// mixes hardened and normal derivation
+func TestDeriveBIP32KeyVector(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		path    string
+		wantKey string
+	}{
+		{path: "m/0'", wantKey: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{path: "m/0'/1", wantKey: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{path: "m/0'/1/2'", wantKey: "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, err := accounts.ParseDerivationPath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			privateKey, err := deriveBIP32Key(seed, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if key := hex.EncodeToString(crypto.FromECDSA(privateKey)); key != test.wantKey {
+				t.Fatalf("key %s, want %s", key, test.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadSignerFromHex(t *testing.T) {
+	for name, hexKey := range map[string]string{
+		"plain":          testHardhatKey0,
+		"0x prefix":      "0x" + testHardhatKey0,
+		"0X prefix":      "0X" + testHardhatKey0,
+		"whitespace":     "  0x" + testHardhatKey0 + "\n",
+		"trailing CR LF": testHardhatKey0 + "\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			const envName = "GOETH_TX_HELPER_TEST_KEY"
+			t.Setenv(envName, hexKey)
+
+			signer, err := LoadSignerFromHexEnv(envName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if signer.Address() != testHardhatAddress0 {
+				t.Fatalf("env: address %s, want %s", signer.Address().Hex(), testHardhatAddress0.Hex())
+			}
+
+			keyFile := filepath.Join(t.TempDir(), "key.hex")
+			if err = os.WriteFile(keyFile, []byte(hexKey), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			signer, err = LoadSignerFromHexFile(keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if signer.Address() != testHardhatAddress0 {
+				t.Fatalf("file: address %s, want %s", signer.Address().Hex(), testHardhatAddress0.Hex())
+			}
+		})
+	}
+}
+
+func TestLoadSignerFromHexErrors(t *testing.T) {
+	const envName = "GOETH_TX_HELPER_TEST_KEY"
+
+	t.Setenv(envName, "")
+	if _, err := LoadSignerFromHexEnv(envName); err == nil {
+		t.Fatal("expected error for empty environment variable")
+	}
+
+	for name, hexKey := range map[string]string{
+		"not hex":   "0xzz" + testHardhatKey0[4:],
+		"too short": testHardhatKey0[:62],
+		"zero key":  "0x0000000000000000000000000000000000000000000000000000000000000000",
+	} {
+		t.Setenv(envName, hexKey)
+
+		if _, err := LoadSignerFromHexEnv(envName); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	if _, err := LoadSignerFromHexFile(filepath.Join(t.TempDir(), "missing.hex")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestLoadSignerFromKeystore(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testHardhatKey0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+
+	account, err := ks.ImportECDSA(privateKey, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyJSON, err := ks.Export(account, "passphrase", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := LoadSignerFromKeystoreJSON(keyJSON, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signer.Address() != testHardhatAddress0 {
+		t.Fatalf("address %s, want %s", signer.Address().Hex(), testHardhatAddress0.Hex())
+	}
+
+	if _, err = LoadSignerFromKeystoreJSON(keyJSON, "wrong passphrase"); err == nil {
+		t.Fatal("expected error for wrong passphrase")
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "keystore.json")
+	if err = os.WriteFile(keyFile, keyJSON, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err = LoadSignerFromKeystoreFile(keyFile, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signer.Address() != testHardhatAddress0 {
+		t.Fatalf("file: address %s, want %s", signer.Address().Hex(), testHardhatAddress0.Hex())
+	}
+}
@@ -0,0 +1,147 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"time"
+)
+
+// DigestSigner is the minimal interface of cloud KMS (AWS KMS, GCP KMS, Azure Key Vault, HSM):
+// it signs 32-byte digest with secp256k1 key and returns ASN.1 DER encoded ECDSA signature (without recovery id)
+type DigestSigner interface {
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+var secp256k1N = crypto.S256().Params().N
+var secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+
+// defaultKMSSignerTimeout - time limit for one KMS signing request
+const defaultKMSSignerTimeout = 30 * time.Second
+
+// KMSSigner adapts DigestSigner to Signer: DER signature is converted to Ethereum format
+// (S normalized to low-S, V recovered by matching expected address)
+type KMSSigner struct {
+	kms     DigestSigner
+	address common.Address
+	timeout time.Duration
+}
+
+// ecdsaSignature - ASN.1 structure of DER encoded ECDSA signature
+type ecdsaSignature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// subjectPublicKeyInfo - ASN.1 structure of DER encoded public key, as KMS services return it
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// NewKMSSigner creates signer for KMS key which belongs to given address (see GetPublicAddressFromDERPublicKey)
+func NewKMSSigner(kms DigestSigner, address common.Address) (*KMSSigner, error) {
+	if kms == nil {
+		return nil, fmt.Errorf("kms digest signer must not be nil")
+	}
+
+	return &KMSSigner{
+		kms:     kms,
+		address: address,
+		timeout: defaultKMSSignerTimeout,
+	}, nil
+}
+
+// GetPublicAddressFromDERPublicKey returns address of secp256k1 public key in DER (SubjectPublicKeyInfo) format,
+// as it is returned by KMS "get public key" calls
+func GetPublicAddressFromDERPublicKey(derPublicKey []byte) (common.Address, error) {
+	var publicKeyInfo subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(derPublicKey, &publicKeyInfo); err != nil {
+		return common.Address{}, fmt.Errorf("failed to parse DER public key: %s", err)
+	}
+
+	publicKey, err := crypto.UnmarshalPubkey(publicKeyInfo.PublicKey.Bytes)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to parse secp256k1 public key: %s", err)
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// SetTimeout sets time limit for one KMS signing request (default 30 seconds)
+func (s *KMSSigner) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+func (s *KMSSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KMSSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	txSigner := types.LatestSignerForChainID(chainID)
+	digest := txSigner.Hash(tx)
+
+	derSignature, err := s.kms.SignDigest(ctx, digest.Bytes())
+	if err != nil {
+		return nil, WrapExternalError(err, "kms failed to sign transaction digest")
+	}
+
+	signature, err := derSignatureToEthereum(derSignature, digest.Bytes(), s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.WithSignature(txSigner, signature)
+}
+
+// derSignatureToEthereum converts DER signature to 65-byte [R || S || V] format: S is normalized to low-S (EIP-2),
+// V (recovery id) is found by recovering public key and matching it with expected address
+func derSignatureToEthereum(derSignature []byte, digest []byte, expectedAddress common.Address) ([]byte, error) {
+	var parsed ecdsaSignature
+
+	rest, err := asn1.Unmarshal(derSignature, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DER signature: %s", err)
+	}
+
+	if len(rest) > 0 || parsed.R == nil || parsed.S == nil || parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 {
+		return nil, fmt.Errorf("malformed DER signature")
+	}
+
+	// Signature comes from outside, R and S out of curve order would not fit into 32 bytes (or be invalid anyway)
+	if parsed.R.Cmp(secp256k1N) >= 0 || parsed.S.Cmp(secp256k1N) >= 0 {
+		return nil, fmt.Errorf("malformed DER signature: R or S is not less than curve order")
+	}
+
+	s := new(big.Int).Set(parsed.S)
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(secp256k1N, s) // (r, s) and (r, n-s) are both valid, Ethereum accepts only low-S
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	parsed.R.FillBytes(signature[0:32])
+	s.FillBytes(signature[32:64])
+
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+
+		publicKey, err := crypto.SigToPub(digest, signature)
+		if err != nil {
+			continue
+		}
+
+		if crypto.PubkeyToAddress(*publicKey) == expectedAddress {
+			return signature, nil
+		}
+	}
+
+	return nil, fmt.Errorf("kms signature does not match address %s (wrong key?)", expectedAddress.Hex())
+}
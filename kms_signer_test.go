@@ -0,0 +1,174 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// secp256k1OID - DER encoded curve OID 1.3.132.0.10
+var secp256k1OID = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// localDigestSigner implements DigestSigner with local private key, producing DER signatures exactly like KMS does.
+// KMS does not normalize S, so with highS set it returns (r, n-s) - the other valid form of the same signature.
+type localDigestSigner struct {
+	privateKey *ecdsa.PrivateKey
+	highS      bool
+}
+
+func (l *localDigestSigner) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	signature, err := crypto.Sign(digest, l.privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(big.Int).SetBytes(signature[32:64])
+	if l.highS {
+		s.Sub(secp256k1N, s)
+	}
+
+	return asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(signature[0:32]),
+		S: s,
+	})
+}
+
+// derPublicKey returns public key in DER (SubjectPublicKeyInfo) format, like KMS "get public key" does
+func (l *localDigestSigner) derPublicKey() ([]byte, error) {
+	publicKey := crypto.FromECDSAPub(&l.privateKey.PublicKey)
+
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, // id-ecPublicKey
+			Parameters: asn1.RawValue{FullBytes: secp256k1OID},
+		},
+		PublicKey: asn1.BitString{
+			Bytes:     publicKey,
+			BitLength: 8 * len(publicKey),
+		},
+	})
+}
+
+func newTestDigestSigner(t *testing.T, highS bool) *localDigestSigner {
+	t.Helper()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &localDigestSigner{privateKey: privateKey, highS: highS}
+}
+
+func TestGetPublicAddressFromDERPublicKey(t *testing.T) {
+	digestSigner := newTestDigestSigner(t, false)
+
+	derPublicKey, err := digestSigner.derPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := GetPublicAddressFromDERPublicKey(derPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := crypto.PubkeyToAddress(digestSigner.privateKey.PublicKey); address != expected {
+		t.Fatalf("expected address %s, got %s", expected.Hex(), address.Hex())
+	}
+}
+
+func TestKMSSignerSignTx(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chainID := big.NewInt(1337)
+
+	for _, highS := range []bool{false, true} {
+		digestSigner := newTestDigestSigner(t, highS)
+		address := crypto.PubkeyToAddress(digestSigner.privateKey.PublicKey)
+
+		kmsSigner, err := NewKMSSigner(digestSigner, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Several transactions, so both recovery ids (V = 0 and V = 1) are most likely met
+		for nonce := uint64(0); nonce < 8; nonce++ {
+			tx := buildTransaction(chainID, nonce, Gas1559Params{
+				GasTipCap: big.NewInt(1_000_000_000),
+				GasFeeCap: big.NewInt(3_000_000_000),
+				Gas:       21_000,
+			}, &to, big.NewInt(1), nil)
+
+			signedTx, err := kmsSigner.SignTx(tx, chainID)
+			if err != nil {
+				t.Fatalf("highS=%t: %s", highS, err)
+			}
+
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+			if err != nil {
+				t.Fatalf("highS=%t: %s", highS, err)
+			}
+
+			if sender != address {
+				t.Fatalf("highS=%t: expected sender %s, got %s", highS, address.Hex(), sender.Hex())
+			}
+
+			if _, _, s := signedTx.RawSignatureValues(); s.Cmp(secp256k1HalfN) > 0 {
+				t.Fatalf("highS=%t: S is not normalized to low-S", highS)
+			}
+		}
+	}
+}
+
+func TestKMSSignerRejectsWrongKey(t *testing.T) {
+	digestSigner := newTestDigestSigner(t, false)
+	otherAddress := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+	kmsSigner, err := NewKMSSigner(digestSigner, otherAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tx := buildTransaction(big.NewInt(1), 0, Gas1559Params{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000}, &to, big.NewInt(1), nil)
+
+	if _, err = kmsSigner.SignTx(tx, big.NewInt(1)); err == nil {
+		t.Fatal("expected error for signature made by key of another address")
+	}
+}
+
+func TestDERSignatureToEthereumRejectsMalformedSignature(t *testing.T) {
+	digest := crypto.Keccak256([]byte("digest"))
+
+	for name, derSignature := range map[string][]byte{
+		"garbage":               {0x01, 0x02, 0x03},
+		"trailing data":         append(mustMarshalECDSASignature(t, big.NewInt(1), big.NewInt(1)), 0x00),
+		"zero S":                mustMarshalECDSASignature(t, big.NewInt(1), big.NewInt(0)),
+		"R wider than 32 bytes": mustMarshalECDSASignature(t, new(big.Int).Lsh(big.NewInt(1), 260), big.NewInt(1)),
+		"R equal to N":          mustMarshalECDSASignature(t, secp256k1N, big.NewInt(1)),
+		"S equal to N":          mustMarshalECDSASignature(t, big.NewInt(1), secp256k1N),
+		"S wider than 32 bytes": mustMarshalECDSASignature(t, big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 260)),
+	} {
+		if _, err := derSignatureToEthereum(derSignature, digest, common.Address{}); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func mustMarshalECDSASignature(t *testing.T, r *big.Int, s *big.Int) []byte {
+	t.Helper()
+
+	derSignature, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return derSignature
+}
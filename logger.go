@@ -0,0 +1,36 @@
+package goeth_tx_helper
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+// Logger receives messages helper can't return as errors (e.g. connection failure in CreateEIP1559TxHelper).
+// *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	loggerLock    sync.RWMutex
+	packageLogger Logger = log.New(os.Stderr, "goeth-tx-helper: ", log.LstdFlags)
+)
+
+// SetLogger replaces package logger (by default - standard logger writing to stderr). nil disables logging.
+func SetLogger(logger Logger) {
+	loggerLock.Lock()
+	defer loggerLock.Unlock()
+
+	packageLogger = logger
+}
+
+func logf(format string, v ...interface{}) {
+	loggerLock.RLock()
+	logger := packageLogger
+	loggerLock.RUnlock()
+
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
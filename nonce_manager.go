@@ -0,0 +1,358 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"sort"
+	"sync"
+)
+
+// nonceManager hands out nonces locally (per address), so concurrent senders using the same key never get the same nonce.
+//
+// Node is asked for nonce (PendingNonceAt) only for the first transaction of an address, and again after failed send (resync).
+// Resync is postponed until there are no nonces "in flight" (handed out, but not yet sent), otherwise we could hand out
+// the same nonce twice. Resync never moves local counter below the highest broadcast nonce: node's pending nonce does not
+// count transactions queued behind a gap, so it can be lower than nonces we have already sent.
+//
+// Nonces of transactions built for offline signing (BuildTransaction) stay reserved until the signed transaction is
+// broadcast (BroadcastRawTransaction) or the nonce is given back (ReleaseBuiltNonce); till then they are reported as gaps.
+type nonceManager struct {
+	lock     sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+type accountNonces struct {
+	lock         sync.Mutex
+	synced       bool                // false -> next nonce must be requested from node
+	needResync   bool                // send failed, resync as soon as there are no nonces in flight
+	next         uint64              // next nonce to hand out (if there are no gaps to fill)
+	inFlight     map[uint64]struct{} // handed out, but not sent yet
+	unusedNonces map[uint64]struct{} // handed out, but never broadcast, while greater nonces were handed out (gaps)
+	builtNonces  map[uint64]struct{} // handed out for offline signing, not broadcast yet (never handed out again)
+
+	broadcastNonces map[uint64]struct{} // broadcast, but node's pending nonce has not passed them yet (at last sync)
+	highWater       uint64              // highest broadcast nonce + 1, next is never lowered below it
+}
+
+// NonceGapError reports nonces of given address which were never broadcast, while
+// transactions with greater nonces exist. Such transactions are stuck until gaps are filled.
+type NonceGapError struct {
+	Address common.Address
+	Gaps    []uint64
+}
+
+func (e *NonceGapError) Error() string {
+	return fmt.Sprintf("nonce gap detected for address %s: missing nonces %v", e.Address.Hex(), e.Gaps)
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+func (nm *nonceManager) account(address common.Address) *accountNonces {
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+
+	account, ok := nm.accounts[address]
+	if !ok {
+		account = &accountNonces{
+			inFlight:        make(map[uint64]struct{}),
+			unusedNonces:    make(map[uint64]struct{}),
+			builtNonces:     make(map[uint64]struct{}),
+			broadcastNonces: make(map[uint64]struct{}),
+		}
+		nm.accounts[address] = account
+	}
+
+	return account
+}
+
+// acquire returns nonce for the next transaction of given address. Every acquired nonce MUST be released.
+// Gaps (nonces handed out earlier, but not sent) are handed out first, lowest one first.
+func (nm *nonceManager) acquire(ctx context.Context, backend TxHelperBackend, address common.Address) (uint64, error) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	if !account.synced || (account.needResync && len(account.inFlight) == 0) {
+		if err := account.sync(ctx, backend, address); err != nil {
+			return 0, err
+		}
+	}
+
+	var nonce uint64
+	if gaps := account.gaps(); len(gaps) > 0 {
+		nonce = gaps[0]
+		delete(account.unusedNonces, nonce)
+	} else {
+		nonce = account.next
+		account.next++
+	}
+
+	account.inFlight[nonce] = struct{}{}
+
+	return nonce, nil
+}
+
+// peek returns nonce acquire would return now, without handing it out
+func (nm *nonceManager) peek(ctx context.Context, backend TxHelperBackend, address common.Address) (uint64, error) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	if !account.synced || (account.needResync && len(account.inFlight) == 0) {
+		if err := account.sync(ctx, backend, address); err != nil {
+			return 0, err
+		}
+	}
+
+	if gaps := account.gaps(); len(gaps) > 0 {
+		return gaps[0], nil
+	}
+
+	return account.next, nil
+}
+
+// release must be called for every acquired nonce when it is known whether transaction was broadcast
+func (nm *nonceManager) release(address common.Address, nonce uint64, broadcast bool) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	delete(account.inFlight, nonce)
+
+	if broadcast {
+		account.markBroadcast(nonce)
+	} else {
+		account.giveBack(nonce)
+	}
+}
+
+// reserveBuilt must be called instead of release for acquired nonce of transaction built for offline signing
+func (nm *nonceManager) reserveBuilt(address common.Address, nonce uint64) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	delete(account.inFlight, nonce)
+	account.builtNonces[nonce] = struct{}{}
+}
+
+// releaseBuilt reports whether transaction built for offline signing was broadcast, or its nonce is given back.
+// Broadcast of transaction with nonce which was not built by helper is recorded as well (node has got it anyway).
+func (nm *nonceManager) releaseBuilt(address common.Address, nonce uint64, broadcast bool) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	_, built := account.builtNonces[nonce]
+	delete(account.builtNonces, nonce)
+
+	if broadcast {
+		account.markBroadcast(nonce)
+	} else if built {
+		account.giveBack(nonce)
+	}
+}
+
+// forceResync makes next acquire request nonce from node (as soon as there are no nonces in flight)
+func (nm *nonceManager) forceResync(address common.Address) {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	account.needResync = true
+}
+
+// gapsAt returns nonces in range [nodePendingNonce, next) which are neither in flight nor broadcast - node has never got them.
+// Built, but not broadcast nonces are gaps too.
+func (nm *nonceManager) gapsAt(address common.Address, nodePendingNonce uint64) []uint64 {
+	account := nm.account(address)
+
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	gaps := make([]uint64, 0)
+
+	if !account.synced {
+		return gaps
+	}
+
+	for nonce := nodePendingNonce; nonce < account.next; nonce++ {
+		if _, ok := account.inFlight[nonce]; ok {
+			continue
+		}
+
+		if _, ok := account.broadcastNonces[nonce]; ok {
+			continue
+		}
+
+		gaps = append(gaps, nonce)
+	}
+
+	return gaps
+}
+
+// sync takes nonce from node, must be called under account lock
+func (account *accountNonces) sync(ctx context.Context, backend TxHelperBackend, address common.Address) error {
+	pendingNonce, err := backend.PendingNonceAt(ctx, address)
+	if err != nil {
+		return WrapExternalError(err, "failed to get nonce")
+	}
+
+	// Everything below node's pending nonce is already used (by us or somebody else). Above it, node may hold our
+	// transactions queued behind a gap, so local counter is not lowered below the highest broadcast nonce,
+	// and unused nonces there are kept - they are exactly the gaps to fill.
+	account.next = pendingNonce
+	if account.highWater > account.next {
+		account.next = account.highWater
+	}
+
+	for nonce := range account.builtNonces {
+		if nonce < pendingNonce {
+			delete(account.builtNonces, nonce) // Used by somebody else, transaction built with it can't be mined
+		} else if nonce+1 > account.next {
+			account.next = nonce + 1
+		}
+	}
+
+	for nonce := range account.unusedNonces {
+		if nonce < pendingNonce || nonce >= account.next {
+			delete(account.unusedNonces, nonce)
+		}
+	}
+
+	for nonce := range account.broadcastNonces {
+		if nonce < pendingNonce {
+			delete(account.broadcastNonces, nonce)
+		}
+	}
+
+	account.synced = true
+	account.needResync = false
+
+	return nil
+}
+
+// markBroadcast records that node has got transaction with given nonce, must be called under account lock
+func (account *accountNonces) markBroadcast(nonce uint64) {
+	account.broadcastNonces[nonce] = struct{}{}
+
+	if nonce+1 > account.highWater {
+		account.highWater = nonce + 1
+	}
+}
+
+// giveBack returns handed out nonce which was never broadcast, must be called under account lock
+func (account *accountNonces) giveBack(nonce uint64) {
+	if nonce+1 == account.next {
+		account.next-- // It was the last handed out nonce, just take it back, no gap is created
+	} else {
+		account.unusedNonces[nonce] = struct{}{}
+	}
+
+	account.needResync = true
+}
+
+func (account *accountNonces) gaps() []uint64 {
+	gaps := make([]uint64, 0, len(account.unusedNonces))
+
+	for nonce := range account.unusedNonces {
+		gaps = append(gaps, nonce)
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+
+	return gaps
+}
+
+// acquireNonce takes nonce from local nonce manager (if enabled, see WithNonceManager) or directly from node
+func (eipHelper *EIP1559TransactionHelper) acquireNonce(ctx context.Context, from common.Address) (uint64, error) {
+	if eipHelper.nonceManager == nil {
+		nonce, err := eipHelper.backend.PendingNonceAt(ctx, from)
+		if err != nil {
+			return 0, WrapExternalError(err, "failed to get nonce")
+		}
+
+		return nonce, nil
+	}
+
+	return eipHelper.nonceManager.acquire(ctx, eipHelper.backend, from)
+}
+
+// peekNonce returns nonce the next transaction would get, without reserving it
+func (eipHelper *EIP1559TransactionHelper) peekNonce(ctx context.Context, from common.Address) (uint64, error) {
+	if eipHelper.nonceManager == nil {
+		nonce, err := eipHelper.backend.PendingNonceAt(ctx, from)
+		if err != nil {
+			return 0, WrapExternalError(err, "failed to get nonce")
+		}
+
+		return nonce, nil
+	}
+
+	return eipHelper.nonceManager.peek(ctx, eipHelper.backend, from)
+}
+
+// releaseNonce reports to nonce manager whether transaction with acquired nonce was broadcast
+func (eipHelper *EIP1559TransactionHelper) releaseNonce(from common.Address, nonce uint64, broadcast bool) {
+	if eipHelper.nonceManager == nil {
+		return
+	}
+
+	eipHelper.nonceManager.release(from, nonce, broadcast)
+}
+
+// ReleaseBuiltNonce gives back nonce of transaction built by BuildTransaction, which will never be broadcast
+// (e.g. cold wallet refused to sign it), so the nonce is handed out to the next transaction instead of leaving a gap.
+// Does nothing if nonce manager is disabled.
+func (eipHelper *EIP1559TransactionHelper) ReleaseBuiltNonce(address common.Address, nonce uint64) {
+	if eipHelper.nonceManager == nil {
+		return
+	}
+
+	eipHelper.nonceManager.releaseBuilt(address, nonce, false)
+}
+
+// ResyncNonce makes local nonce manager request nonce of given address from node before the next transaction.
+// Use it if the same key is used for sending outside of this helper. Does nothing if nonce manager is disabled.
+func (eipHelper *EIP1559TransactionHelper) ResyncNonce(address common.Address) {
+	if eipHelper.nonceManager == nil {
+		return
+	}
+
+	eipHelper.nonceManager.forceResync(address)
+}
+
+// DetectNonceGaps compares nonces handed out by local nonce manager with pending nonce known by node.
+// Returns *NonceGapError if there are nonces node has not seen (transactions with greater nonces are stuck because of them),
+// including nonces of built transactions which were not broadcast yet (see BuildTransaction).
+// Always returns nil if nonce manager is disabled.
+func (eipHelper *EIP1559TransactionHelper) DetectNonceGaps(ctx context.Context, address common.Address) error {
+	if eipHelper.nonceManager == nil {
+		return nil
+	}
+
+	pendingNonce, err := eipHelper.backend.PendingNonceAt(ctx, address)
+	if err != nil {
+		return WrapExternalError(err, "failed to get nonce")
+	}
+
+	if gaps := eipHelper.nonceManager.gapsAt(address, pendingNonce); len(gaps) > 0 {
+		return &NonceGapError{
+			Address: address,
+			Gaps:    gaps,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeNonceBackend - node that knows pending nonce only: transactions queued behind a gap do not move it
+type fakeNonceBackend struct {
+	TxHelperBackend // Not used by nonce manager, calls of other methods panic
+
+	lock     sync.Mutex
+	received map[uint64]bool
+	base     uint64 // Nonces below base are considered mined (sent outside of helper)
+}
+
+func newFakeNonceBackend() *fakeNonceBackend {
+	return &fakeNonceBackend{received: make(map[uint64]bool)}
+}
+
+func (backend *fakeNonceBackend) PendingNonceAt(_ context.Context, _ common.Address) (uint64, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	nonce := backend.base
+	for backend.received[nonce] {
+		nonce++
+	}
+
+	return nonce, nil
+}
+
+func (backend *fakeNonceBackend) send(nonce uint64) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	backend.received[nonce] = true
+}
+
+var testNonceAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func TestNonceManagerConcurrentAcquire(t *testing.T) {
+	backend := newFakeNonceBackend()
+	nm := newNonceManager()
+
+	const senders = 50
+
+	var wg sync.WaitGroup
+	nonces := make(chan uint64, senders)
+
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			nonce, err := nm.acquire(context.Background(), backend, testNonceAddress)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			backend.send(nonce)
+			nm.release(testNonceAddress, nonce, true)
+			nonces <- nonce
+		}()
+	}
+
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[uint64]bool)
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %d handed out twice", nonce)
+		}
+		seen[nonce] = true
+	}
+
+	for nonce := uint64(0); nonce < senders; nonce++ {
+		if !seen[nonce] {
+			t.Fatalf("nonce %d was not handed out", nonce)
+		}
+	}
+}
+
+func TestNonceManagerFailedSendDoesNotReuseBroadcastNonce(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeNonceBackend()
+	backend.base = 5
+	nm := newNonceManager()
+
+	first, _ := nm.acquire(ctx, backend, testNonceAddress)
+	second, _ := nm.acquire(ctx, backend, testNonceAddress)
+	if first != 5 || second != 6 {
+		t.Fatalf("expected nonces 5 and 6, got %d and %d", first, second)
+	}
+
+	// 6 is broadcast, 5 fails: node's pending nonce stays 5, while 6 is queued behind the gap
+	backend.send(second)
+	nm.release(testNonceAddress, second, true)
+	nm.release(testNonceAddress, first, false)
+
+	if gaps := nm.gapsAt(testNonceAddress, 5); len(gaps) != 1 || gaps[0] != 5 {
+		t.Fatalf("expected gaps [5], got %v", gaps)
+	}
+
+	var sent []uint64
+	for i := 0; i < 2; i++ {
+		nonce, err := nm.acquire(ctx, backend, testNonceAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		backend.send(nonce)
+		nm.release(testNonceAddress, nonce, true)
+		sent = append(sent, nonce)
+	}
+
+	if sent[0] != 5 || sent[1] != 7 {
+		t.Fatalf("expected nonces 5 and 7 after failed send, got %v", sent)
+	}
+
+	pendingNonce, _ := backend.PendingNonceAt(ctx, testNonceAddress)
+	if gaps := nm.gapsAt(testNonceAddress, pendingNonce); len(gaps) != 0 {
+		t.Fatalf("expected no gaps, got %v", gaps)
+	}
+}
+
+func TestNonceManagerResync(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeNonceBackend()
+	nm := newNonceManager()
+
+	nonce, _ := nm.acquire(ctx, backend, testNonceAddress)
+	backend.send(nonce)
+	nm.release(testNonceAddress, nonce, true)
+
+	// The same key sent transactions outside of helper
+	backend.base = 10
+
+	nonce, _ = nm.acquire(ctx, backend, testNonceAddress)
+	nm.release(testNonceAddress, nonce, true)
+	if nonce != 1 {
+		t.Fatalf("expected local nonce 1 before resync, got %d", nonce)
+	}
+
+	nm.forceResync(testNonceAddress)
+
+	if peeked, _ := nm.peek(ctx, backend, testNonceAddress); peeked != 10 {
+		t.Fatalf("expected peeked nonce 10 after resync, got %d", peeked)
+	}
+
+	nonce, _ = nm.acquire(ctx, backend, testNonceAddress)
+	nm.release(testNonceAddress, nonce, true)
+	if nonce != 10 {
+		t.Fatalf("expected nonce 10 after resync, got %d", nonce)
+	}
+}
+
+func TestNonceManagerResyncWaitsForInFlight(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeNonceBackend()
+	nm := newNonceManager()
+
+	inFlight, _ := nm.acquire(ctx, backend, testNonceAddress)
+	failed, _ := nm.acquire(ctx, backend, testNonceAddress)
+	nm.release(testNonceAddress, failed, false)
+
+	// Resync is pending, but nonce 0 is still in flight, so local counter is used
+	nonce, _ := nm.acquire(ctx, backend, testNonceAddress)
+	if nonce != 1 {
+		t.Fatalf("expected nonce 1, got %d", nonce)
+	}
+
+	for _, n := range []uint64{inFlight, nonce} {
+		backend.send(n)
+		nm.release(testNonceAddress, n, true)
+	}
+
+	nonce, _ = nm.acquire(ctx, backend, testNonceAddress)
+	if nonce != 2 {
+		t.Fatalf("expected nonce 2, got %d", nonce)
+	}
+}
+
+func TestDetectNonceGaps(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeNonceBackend()
+
+	txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithNonceManager(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := txHelper.acquireNonce(ctx, testNonceAddress)
+	second, _ := txHelper.acquireNonce(ctx, testNonceAddress)
+	backend.send(second)
+	txHelper.releaseNonce(testNonceAddress, second, true)
+	txHelper.releaseNonce(testNonceAddress, first, false)
+
+	var gapError *NonceGapError
+	if err = txHelper.DetectNonceGaps(ctx, testNonceAddress); !errors.As(err, &gapError) {
+		t.Fatalf("expected NonceGapError, got %v", err)
+	}
+
+	if len(gapError.Gaps) != 1 || gapError.Gaps[0] != first {
+		t.Fatalf("expected gaps [%d], got %v", first, gapError.Gaps)
+	}
+}
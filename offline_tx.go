@@ -0,0 +1,131 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// BuildTransaction builds UNSIGNED transaction (DynamicFeeTx, or other type, see WithTxType) with nonce (PendingNonceAt, or local nonce manager if enabled)
+// and gas parameters (GetGasParametersWithContext) filled in. It is the first step of offline (air-gapped) signing:
+//
+//	BuildTransaction -> EncodeUnsignedTransaction -> sign on cold wallet -> BroadcastRawTransaction
+//
+// With nonce manager enabled, nonce stays reserved until the signed transaction is broadcast by BroadcastRawTransaction;
+// till then DetectNonceGaps reports it. If transaction will never be broadcast, give the nonce back by ReleaseBuiltNonce.
+func (eipHelper *EIP1559TransactionHelper) BuildTransaction(ctx context.Context, from common.Address, to *common.Address, chainID *big.Int, value *big.Int, data []byte) (*types.Transaction, error) {
+	gasParams, err := eipHelper.GetGasParametersWithContext(ctx, from, to, value, data)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := eipHelper.acquireNonce(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if eipHelper.nonceManager != nil {
+		eipHelper.nonceManager.reserveBuilt(from, nonce)
+	}
+
+	return buildTransaction(chainID, nonce, gasParams, to, value, data), nil
+}
+
+// EncodeUnsignedTransaction encodes transaction (signed or not) into its canonical binary form (typed RLP envelope)
+func EncodeUnsignedTransaction(tx *types.Transaction) ([]byte, error) {
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %s", err)
+	}
+
+	return encoded, nil
+}
+
+// DecodeUnsignedTransaction decodes transaction encoded by EncodeUnsignedTransaction
+func DecodeUnsignedTransaction(encoded []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encoded); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %s", err)
+	}
+
+	return tx, nil
+}
+
+// EncodeTransactionJSON encodes transaction (signed or not) into JSON (the same format as eth_getTransactionByHash uses),
+// human-readable alternative for review before signing
+func EncodeTransactionJSON(tx *types.Transaction) ([]byte, error) {
+	encoded, err := tx.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction to JSON: %s", err)
+	}
+
+	return encoded, nil
+}
+
+// DecodeTransactionJSON decodes transaction encoded by EncodeTransactionJSON
+func DecodeTransactionJSON(encoded []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalJSON(encoded); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction from JSON: %s", err)
+	}
+
+	return tx, nil
+}
+
+// BroadcastRawTransaction sends transaction signed elsewhere (binary form, as returned by types.Transaction.MarshalBinary)
+// and returns PendingTx handle. If waitMined is true, it also waits (within ctx) for transaction to be mined.
+//
+// In emulation mode nothing is sent, returned PendingTx is already "mined" with receipt from emulator.
+func (eipHelper *EIP1559TransactionHelper) BroadcastRawTransaction(ctx context.Context, signedTxBytes []byte, waitMined bool) (*PendingTx, error) {
+	signedTx, err := DecodeUnsignedTransaction(signedTxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("raw transaction has invalid signature: %s", err)
+	}
+
+	if eipHelper.emulation {
+		receipt, err := eipHelper.emulateSend(from, signedTx.To(), signedTx.ChainId(), gasParamsFromTx(signedTx), signedTx.Value(), signedTx.Data())
+		if err != nil {
+			return nil, err
+		}
+
+		eipHelper.markRawBroadcast(from, signedTx.Nonce())
+
+		return newMinedPendingTx(signedTx, from, receipt), nil
+	}
+
+	if err = eipHelper.backend.SendTransaction(ctx, signedTx); err != nil {
+		return nil, &ExternalErrorWrapper{
+			OriginalError:     err,
+			OurMessage:        "failed to send raw transaction",
+			AdditionalContext: fmt.Sprintf("tx hash: %s, nonce: %d", signedTx.Hash().Hex(), signedTx.Nonce()),
+		}
+	}
+
+	eipHelper.markRawBroadcast(from, signedTx.Nonce())
+
+	pendingTx := newPendingTx(eipHelper.backend, signedTx, from)
+
+	if waitMined {
+		if _, err = pendingTx.Wait(ctx); err != nil {
+			return pendingTx, err
+		}
+	}
+
+	return pendingTx, nil
+}
+
+// markRawBroadcast reports to nonce manager that transaction signed elsewhere (maybe built by BuildTransaction) was broadcast
+func (eipHelper *EIP1559TransactionHelper) markRawBroadcast(from common.Address, nonce uint64) {
+	if eipHelper.nonceManager == nil {
+		return
+	}
+
+	eipHelper.nonceManager.releaseBuilt(from, nonce, true)
+}
@@ -0,0 +1,124 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeOfflineBackend - fakeDryRunBackend with nonces of fakeNonceBackend, which also receives raw transactions
+type fakeOfflineBackend struct {
+	fakeDryRunBackend
+
+	nonces *fakeNonceBackend
+}
+
+func (backend *fakeOfflineBackend) PendingNonceAt(ctx context.Context, address common.Address) (uint64, error) {
+	return backend.nonces.PendingNonceAt(ctx, address)
+}
+
+func (backend *fakeOfflineBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	backend.nonces.send(tx.Nonce())
+
+	return nil
+}
+
+func (backend *fakeOfflineBackend) TransactionReceipt(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound // Never mined
+}
+
+func TestBuildTransactionNonceReservedUntilBroadcast(t *testing.T) {
+	ctx := context.Background()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := signer.Address()
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chainID := big.NewInt(1337)
+
+	backend := &fakeOfflineBackend{fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(30)}, nonces: newFakeNonceBackend()}
+	backend.nonces.base = 5
+
+	txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithNonceManager(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := txHelper.BuildTransaction(ctx, from, &to, chainID, big.NewInt(0), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := txHelper.BuildTransaction(ctx, from, &to, chainID, big.NewInt(0), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Nonce() != 5 || second.Nonce() != 6 {
+		t.Fatalf("expected nonces 5 and 6, got %d and %d", first.Nonce(), second.Nonce())
+	}
+
+	// Node has seen none of built transactions
+	var gapError *NonceGapError
+	if err = txHelper.DetectNonceGaps(ctx, from); !errors.As(err, &gapError) {
+		t.Fatalf("expected NonceGapError, got %v", err)
+	}
+
+	if len(gapError.Gaps) != 2 || gapError.Gaps[0] != 5 || gapError.Gaps[1] != 6 {
+		t.Fatalf("expected gaps [5 6], got %v", gapError.Gaps)
+	}
+
+	// Built nonces are not handed out to other transactions, even after resync
+	txHelper.ResyncNonce(from)
+
+	if nonce, _ := txHelper.peekNonce(ctx, from); nonce != 7 {
+		t.Fatalf("expected next nonce 7 while built nonces are reserved, got %d", nonce)
+	}
+
+	// The first one is signed and broadcast, the second one is abandoned
+	signedTx, err := signer.SignTx(first, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTxBytes, err := signedTx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pendingTx, err := txHelper.BroadcastRawTransaction(ctx, signedTxBytes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pendingTx.Stop()
+
+	txHelper.ReleaseBuiltNonce(from, second.Nonce())
+
+	if err = txHelper.DetectNonceGaps(ctx, from); err != nil {
+		t.Fatalf("expected no gaps, got %v", err)
+	}
+
+	nonce, err := txHelper.acquireNonce(ctx, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nonce != 6 {
+		t.Fatalf("expected released nonce 6 to be handed out again, got %d", nonce)
+	}
+}
@@ -0,0 +1,231 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sync"
+)
+
+type PendingTxStatus int
+
+const (
+	PendingTxStatusPending  PendingTxStatus = iota // Broadcast, receipt is not available yet
+	PendingTxStatusMined                           // Mined successfully (receipt status 1)
+	PendingTxStatusReverted                        // Mined, but execution failed (receipt status 0)
+	PendingTxStatusFailed                          // Watching stopped before receipt was received (see PendingTx.Stop)
+)
+
+func (status PendingTxStatus) String() string {
+	switch status {
+	case PendingTxStatusPending:
+		return "pending"
+	case PendingTxStatusMined:
+		return "mined"
+	case PendingTxStatusReverted:
+		return "reverted"
+	case PendingTxStatusFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(status))
+	}
+}
+
+// PendingTx is a handle of broadcast transaction, returned by SendTransactionAsync.
+//
+// Right after creation it starts watching for transaction receipt in background. Watching lasts until receipt is
+// received or Stop is called, so if you are not going to wait for the transaction - call Stop to release resources.
+type PendingTx struct {
+	backend  TxHelperBackend // nil in emulation mode
+	signedTx *types.Transaction
+	from     common.Address
+
+	simulationResult []byte // Data returned by pre-flight simulation (see WithPreflightSimulation)
+
+	mined       chan struct{}      // Closed when watching finished (either receipt received or watching stopped)
+	stopWatcher context.CancelFunc // Stops background watching
+
+	lock    sync.Mutex
+	receipt *types.Receipt
+	err     error
+}
+
+// newPendingTx creates handle for just broadcast transaction and starts watching for its receipt
+func newPendingTx(backend TxHelperBackend, signedTx *types.Transaction, from common.Address) *PendingTx {
+	watcherCtx, stopWatcher := context.WithCancel(context.Background())
+
+	pendingTx := &PendingTx{
+		backend:     backend,
+		signedTx:    signedTx,
+		from:        from,
+		mined:       make(chan struct{}),
+		stopWatcher: stopWatcher,
+	}
+
+	go func() {
+		defer stopWatcher()
+
+		receipt, err := waitMined(watcherCtx, backend, signedTx.Hash())
+		if err != nil {
+			err = &ExternalErrorWrapper{
+				OriginalError:     err,
+				OurMessage:        "transaction probably has not been mined (watching stopped)",
+				AdditionalContext: fmt.Sprintf("tx hash: %s, nonce: %d", signedTx.Hash().Hex(), signedTx.Nonce()),
+			}
+		}
+
+		pendingTx.finish(receipt, err)
+	}()
+
+	return pendingTx
+}
+
+// newMinedPendingTx creates handle for transaction which is already mined (used in emulation mode)
+func newMinedPendingTx(signedTx *types.Transaction, from common.Address, receipt *types.Receipt) *PendingTx {
+	pendingTx := &PendingTx{
+		signedTx:    signedTx,
+		from:        from,
+		mined:       make(chan struct{}),
+		stopWatcher: func() {},
+	}
+
+	pendingTx.finish(receipt, nil)
+
+	return pendingTx
+}
+
+func (pendingTx *PendingTx) finish(receipt *types.Receipt, err error) {
+	pendingTx.lock.Lock()
+	pendingTx.receipt = receipt
+	pendingTx.err = err
+	pendingTx.lock.Unlock()
+
+	close(pendingTx.mined)
+}
+
+// Hash returns hash of the transaction (in emulation mode - TxHash of emulated receipt)
+func (pendingTx *PendingTx) Hash() common.Hash {
+	if pendingTx.signedTx == nil {
+		if receipt := pendingTx.Receipt(); receipt != nil {
+			return receipt.TxHash
+		}
+
+		return common.Hash{}
+	}
+
+	return pendingTx.signedTx.Hash()
+}
+
+// Nonce returns nonce of the transaction (0 in emulation mode)
+func (pendingTx *PendingTx) Nonce() uint64 {
+	if pendingTx.signedTx == nil {
+		return 0
+	}
+
+	return pendingTx.signedTx.Nonce()
+}
+
+// SignedTx returns signed transaction as it was broadcast (nil in emulation mode)
+func (pendingTx *PendingTx) SignedTx() *types.Transaction {
+	return pendingTx.signedTx
+}
+
+// SimulationResult returns data returned by pre-flight simulation, nil if simulation is disabled (see WithPreflightSimulation)
+func (pendingTx *PendingTx) SimulationResult() []byte {
+	return pendingTx.simulationResult
+}
+
+// From returns sender address
+func (pendingTx *PendingTx) From() common.Address {
+	return pendingTx.from
+}
+
+// Mined returns channel which is closed when watching is finished: receipt received or watching stopped (see Status)
+func (pendingTx *PendingTx) Mined() <-chan struct{} {
+	return pendingTx.mined
+}
+
+// Status returns current status of the transaction, does not block
+func (pendingTx *PendingTx) Status() PendingTxStatus {
+	pendingTx.lock.Lock()
+	defer pendingTx.lock.Unlock()
+
+	switch {
+	case pendingTx.receipt != nil && pendingTx.receipt.Status == types.ReceiptStatusSuccessful:
+		return PendingTxStatusMined
+	case pendingTx.receipt != nil:
+		return PendingTxStatusReverted
+	case pendingTx.err != nil:
+		return PendingTxStatusFailed
+	default:
+		return PendingTxStatusPending
+	}
+}
+
+// Receipt returns receipt if transaction is already mined, nil otherwise, does not block
+func (pendingTx *PendingTx) Receipt() *types.Receipt {
+	pendingTx.lock.Lock()
+	defer pendingTx.lock.Unlock()
+
+	return pendingTx.receipt
+}
+
+// Wait blocks until transaction is mined, watching is stopped or ctx is done.
+// Cancelling ctx does NOT stop background watching - Wait can be called again later.
+func (pendingTx *PendingTx) Wait(ctx context.Context) (*types.Receipt, error) {
+	select {
+	case <-pendingTx.mined:
+		pendingTx.lock.Lock()
+		defer pendingTx.lock.Unlock()
+
+		return pendingTx.receipt, pendingTx.err
+	case <-ctx.Done():
+		return nil, &ExternalErrorWrapper{
+			OriginalError:     ctx.Err(),
+			OurMessage:        "transaction has not been mined yet",
+			AdditionalContext: fmt.Sprintf("tx hash: %s", pendingTx.Hash().Hex()),
+		}
+	}
+}
+
+// Stop stops background watching. If receipt was not received yet, status becomes PendingTxStatusFailed.
+func (pendingTx *PendingTx) Stop() {
+	pendingTx.stopWatcher()
+}
+
+// SendTransactionAsync signs (by given Signer, see NewPrivateKeySigner for in-memory key) and broadcasts transaction and returns right after broadcast, without waiting for it to be mined.
+// ctx covers broadcasting only, use returned PendingTx to wait for receipt.
+//
+// In emulation mode returned PendingTx is already "mined" with receipt from emulator.
+func (eipHelper *EIP1559TransactionHelper) SendTransactionAsync(
+	ctx context.Context,
+	signer Signer,
+	to *common.Address,
+	chainID *big.Int,
+	gasParams Gas1559Params,
+	value *big.Int,
+	data []byte,
+) (*PendingTx, error) {
+	from := signer.Address()
+
+	if eipHelper.emulation {
+		receipt, err := eipHelper.emulateSend(from, to, chainID, gasParams, value, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return newMinedPendingTx(nil, from, receipt), nil
+	}
+
+	signedTx, simulationResult, err := eipHelper.signAndSend(ctx, signer, to, chainID, gasParams, value, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingTx := newPendingTx(eipHelper.backend, signedTx, from)
+	pendingTx.simulationResult = simulationResult
+
+	return pendingTx, nil
+}
@@ -0,0 +1,253 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"time"
+)
+
+const (
+	RemoteSignMethodClef = "account_signTransaction" // Clef external signer
+	RemoteSignMethodEth  = "eth_signTransaction"     // Node-like signers (geth with unlocked accounts, web3signer, etc.)
+)
+
+// defaultRemoteSignerTimeout - remote signers (like Clef) can wait for manual approval, so timeout is generous
+const defaultRemoteSignerTimeout = 2 * time.Minute
+
+// RemoteSigner forwards unsigned transactions to separate signing process over JSON-RPC (HTTP, WS or IPC),
+// so keys never get into our process memory. Signed transaction returned by remote side is verified:
+// it must be signed by expected address and have exactly the same fields as requested.
+type RemoteSigner struct {
+	client  *rpc.Client
+	method  string
+	address common.Address
+	timeout time.Duration
+}
+
+// remoteSignTxArgs - transaction in form accepted by account_signTransaction (Clef SendTxArgs) and eth_signTransaction
+type remoteSignTxArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to,omitempty"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice,omitempty"` // Legacy and access list transactions
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 *hexutil.Bytes    `json:"data,omitempty"`
+	Input                *hexutil.Bytes    `json:"input,omitempty"` // Newer signers read "input", older - "data", we send both
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+}
+
+type remoteSignTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewRemoteSigner connects to remote signer at endpoint. method is RemoteSignMethodClef or RemoteSignMethodEth.
+//
+// If address is zero address, the first account reported by signer is used (see GetPublicAddressFromRemoteSigner),
+// otherwise signer must manage given address. rpcOptions are passed to rpc.DialOptions (e.g. rpc.WithHTTPClient).
+func NewRemoteSigner(ctx context.Context, endpoint string, method string, address common.Address, rpcOptions ...rpc.ClientOption) (*RemoteSigner, error) {
+	if method != RemoteSignMethodClef && method != RemoteSignMethodEth {
+		return nil, fmt.Errorf("unsupported remote sign method \"%s\"", method)
+	}
+
+	client, err := rpc.DialOptions(ctx, endpoint, rpcOptions...)
+	if err != nil {
+		return nil, WrapExternalError(err, fmt.Sprintf("failed to connect to remote signer \"%s\"", endpoint))
+	}
+
+	remoteSigner := &RemoteSigner{
+		client:  client,
+		method:  method,
+		address: address,
+		timeout: defaultRemoteSignerTimeout,
+	}
+
+	addresses, err := remoteSigner.listAccounts(ctx)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if address == (common.Address{}) {
+		if len(addresses) == 0 {
+			client.Close()
+			return nil, fmt.Errorf("remote signer \"%s\" reported no accounts", endpoint)
+		}
+
+		remoteSigner.address = addresses[0]
+
+		return remoteSigner, nil
+	}
+
+	for _, managed := range addresses {
+		if managed == address {
+			return remoteSigner, nil
+		}
+	}
+
+	client.Close()
+
+	return nil, fmt.Errorf("remote signer \"%s\" does not manage address %s", endpoint, address.Hex())
+}
+
+// GetPublicAddressFromRemoteSigner asks remote signer for its accounts and returns the first one,
+// counterpart of GetPublicAddressFromPrivateKey for keys living in a separate process
+func GetPublicAddressFromRemoteSigner(ctx context.Context, endpoint string, method string, rpcOptions ...rpc.ClientOption) (common.Address, error) {
+	remoteSigner, err := NewRemoteSigner(ctx, endpoint, method, common.Address{}, rpcOptions...)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	defer remoteSigner.Close()
+
+	return remoteSigner.Address(), nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SetTimeout sets time limit for one signing request (default 2 minutes, remote signer can wait for manual approval)
+func (s *RemoteSigner) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// Close closes connection to remote signer
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+func (s *RemoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	data := hexutil.Bytes(tx.Data())
+	accessList := tx.AccessList()
+
+	args := remoteSignTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		Input:   &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+
+	// Signers pick transaction type by fields: maxFeePerGas - DynamicFeeTx, gasPrice + accessList - AccessListTx, gasPrice only - LegacyTx
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		args.AccessList = &accessList
+	default:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		args.AccessList = &accessList
+	}
+
+	var result remoteSignTxResult
+	if err := s.client.CallContext(ctx, &result, s.method, args); err != nil {
+		return nil, WrapExternalError(err, fmt.Sprintf("remote signer failed to sign transaction (%s)", s.method))
+	}
+
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(result.Raw); err != nil {
+		return nil, WrapExternalError(err, "remote signer returned undecodable transaction")
+	}
+
+	if err := verifyRemotelySignedTx(tx, signedTx, chainID, s.address); err != nil {
+		return nil, err
+	}
+
+	return signedTx, nil
+}
+
+func (s *RemoteSigner) listAccounts(ctx context.Context) ([]common.Address, error) {
+	listMethod := "eth_accounts"
+	if s.method == RemoteSignMethodClef {
+		listMethod = "account_list"
+	}
+
+	var addresses []common.Address
+	if err := s.client.CallContext(ctx, &addresses, listMethod); err != nil {
+		return nil, WrapExternalError(err, fmt.Sprintf("failed to get accounts from remote signer (%s)", listMethod))
+	}
+
+	return addresses, nil
+}
+
+// verifyRemotelySignedTx makes sure remote side signed exactly what we asked for, and signed it with expected key
+func verifyRemotelySignedTx(unsignedTx *types.Transaction, signedTx *types.Transaction, chainID *big.Int, expectedSender common.Address) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+	if err != nil {
+		return fmt.Errorf("remotely signed transaction has invalid signature: %s", err)
+	}
+
+	if sender != expectedSender {
+		return fmt.Errorf("remotely signed transaction is signed by %s, expected %s", sender.Hex(), expectedSender.Hex())
+	}
+
+	mismatch := ""
+
+	switch {
+	case signedTx.Type() != unsignedTx.Type():
+		mismatch = "type"
+	case signedTx.ChainId().Cmp(chainID) != 0:
+		mismatch = "chainId"
+	case signedTx.Nonce() != unsignedTx.Nonce():
+		mismatch = "nonce"
+	case signedTx.Gas() != unsignedTx.Gas():
+		mismatch = "gas"
+	case signedTx.GasTipCap().Cmp(unsignedTx.GasTipCap()) != 0:
+		mismatch = "maxPriorityFeePerGas"
+	case signedTx.GasFeeCap().Cmp(unsignedTx.GasFeeCap()) != 0:
+		mismatch = "maxFeePerGas"
+	case signedTx.Value().Cmp(unsignedTx.Value()) != 0:
+		mismatch = "value"
+	case !bytes.Equal(signedTx.Data(), unsignedTx.Data()):
+		mismatch = "data"
+	case (signedTx.To() == nil) != (unsignedTx.To() == nil) || (signedTx.To() != nil && *signedTx.To() != *unsignedTx.To()):
+		mismatch = "to"
+	case !equalAccessLists(signedTx.AccessList(), unsignedTx.AccessList()):
+		mismatch = "accessList" // Changed list changes gas cost and warm slots
+	}
+
+	if mismatch != "" {
+		return fmt.Errorf("remotely signed transaction differs from requested one: field \"%s\" was changed", mismatch)
+	}
+
+	return nil
+}
+
+// equalAccessLists compares access lists entry by entry (nil and empty lists are equal)
+func equalAccessLists(a types.AccessList, b types.AccessList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Address != b[i].Address || len(a[i].StorageKeys) != len(b[i].StorageKeys) {
+			return false
+		}
+
+		for j := range a[i].StorageKeys {
+			if a[i].StorageKeys[j] != b[i].StorageKeys[j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newFakeRemoteSigner starts JSON-RPC server signing transactions with local key, like Clef would.
+// tamper (optional) modifies transaction before signing, as malicious or buggy signer could do.
+func newFakeRemoteSigner(t *testing.T, tamper func(tx *types.DynamicFeeTx)) (*httptest.Server, common.Address) {
+	t.Helper()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage    `json:"id"`
+			Method string             `json:"method"`
+			Params []remoteSignTxArgs `json:"params"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+
+		switch request.Method {
+		case "account_list", "eth_accounts":
+			result = []common.Address{address}
+		case RemoteSignMethodClef, RemoteSignMethodEth:
+			args := request.Params[0]
+
+			unsignedTx := &types.DynamicFeeTx{
+				ChainID:   (*big.Int)(args.ChainID),
+				Nonce:     uint64(args.Nonce),
+				GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+				GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+				Gas:       uint64(args.Gas),
+				To:        args.To,
+				Value:     (*big.Int)(args.Value),
+				Data:      *args.Input,
+			}
+
+			if args.AccessList != nil {
+				unsignedTx.AccessList = *args.AccessList
+			}
+
+			if tamper != nil {
+				tamper(unsignedTx)
+			}
+
+			signedTx, err := types.SignNewTx(privateKey, types.LatestSignerForChainID(unsignedTx.ChainID), unsignedTx)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			raw, _ := signedTx.MarshalBinary()
+			result = map[string]interface{}{"raw": hexutil.Bytes(raw)}
+		default:
+			http.Error(w, "unknown method", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, address
+}
+
+func testRemoteSignTx() (*types.Transaction, *big.Int) {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chainID := big.NewInt(1337)
+
+	return buildTransaction(chainID, 3, Gas1559Params{
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(3_000_000_000),
+		Gas:       50_000,
+		AccessList: types.AccessList{
+			{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x01")}},
+		},
+	}, &to, big.NewInt(42), []byte{0xde, 0xad}), chainID
+}
+
+func TestRemoteSignerSignsRequestedTransaction(t *testing.T) {
+	server, address := newFakeRemoteSigner(t, nil)
+
+	for _, method := range []string{RemoteSignMethodClef, RemoteSignMethodEth} {
+		remoteSigner, err := NewRemoteSigner(context.Background(), server.URL, method, common.Address{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if remoteSigner.Address() != address {
+			t.Fatalf("expected address %s, got %s", address.Hex(), remoteSigner.Address().Hex())
+		}
+
+		tx, chainID := testRemoteSignTx()
+
+		signedTx, err := remoteSigner.SignTx(tx, chainID)
+		if err != nil {
+			t.Fatalf("%s: %s", method, err)
+		}
+
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+		if err != nil || sender != address {
+			t.Fatalf("%s: expected sender %s, got %s (%v)", method, address.Hex(), sender.Hex(), err)
+		}
+
+		if signedTx.Nonce() != tx.Nonce() || !equalAccessLists(signedTx.AccessList(), tx.AccessList()) {
+			t.Fatalf("%s: signed transaction does not match requested one", method)
+		}
+
+		remoteSigner.Close()
+	}
+}
+
+func TestRemoteSignerRejectsTamperedTransaction(t *testing.T) {
+	tampers := map[string]func(tx *types.DynamicFeeTx){
+		"value": func(tx *types.DynamicFeeTx) {
+			tx.Value = big.NewInt(1_000_000)
+		},
+		"to": func(tx *types.DynamicFeeTx) {
+			attacker := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+			tx.To = &attacker
+		},
+		"accessList": func(tx *types.DynamicFeeTx) {
+			tx.AccessList = append(tx.AccessList, types.AccessTuple{Address: common.HexToAddress("0x00000000000000000000000000000000000000ee")})
+		},
+	}
+
+	for field, tamper := range tampers {
+		server, _ := newFakeRemoteSigner(t, tamper)
+
+		remoteSigner, err := NewRemoteSigner(context.Background(), server.URL, RemoteSignMethodClef, common.Address{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tx, chainID := testRemoteSignTx()
+
+		_, err = remoteSigner.SignTx(tx, chainID)
+		if err == nil || !strings.Contains(err.Error(), "\""+field+"\"") {
+			t.Fatalf("expected mismatch of field \"%s\", got %v", field, err)
+		}
+
+		remoteSigner.Close()
+	}
+}
+
+func TestRemoteSignerRejectsUnmanagedAddress(t *testing.T) {
+	server, _ := newFakeRemoteSigner(t, nil)
+
+	_, err := NewRemoteSigner(context.Background(), server.URL, RemoteSignMethodEth, common.HexToAddress("0x00000000000000000000000000000000000000cc"))
+	if err == nil {
+		t.Fatal("expected error for address not managed by remote signer")
+	}
+}
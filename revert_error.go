@@ -0,0 +1,234 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"strings"
+)
+
+var errorStringSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+// panicReasons - Solidity panic codes, see https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertError is returned when contract execution reverted, it holds decoded revert data:
+//
+//	Reason          - message of require(..., "message") / revert("message"), a.k.a. Error(string)
+//	PanicCode       - code of Panic(uint256) (assert, overflow, division by zero, etc.), Reason holds its description
+//	CustomError     - custom error (error MyError(...)) resolved from supplied ABI, its arguments are in CustomErrorArgs
+//
+// If revert data could not be decoded, only Data is set.
+type RevertError struct {
+	Data            []byte
+	Reason          string
+	PanicCode       *big.Int
+	CustomError     *abi.Error
+	CustomErrorArgs []interface{}
+
+	cause error // Original node error, if revert came from node response
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.CustomError != nil:
+		return fmt.Sprintf("execution reverted: %s%v", e.CustomError.Name, e.CustomErrorArgs)
+	case e.PanicCode != nil:
+		return fmt.Sprintf("execution reverted: panic %#x (%s)", e.PanicCode, e.Reason)
+	case e.Reason != "":
+		return fmt.Sprintf("execution reverted: %s", e.Reason)
+	case len(e.Data) > 0:
+		return fmt.Sprintf("execution reverted: unknown revert data %s", hexutil.Encode(e.Data))
+	default:
+		return "execution reverted"
+	}
+}
+
+// Unwrap returns original node error (if any)
+func (e *RevertError) Unwrap() error {
+	return e.cause
+}
+
+// DecodeRevertData decodes revert data returned by contract: Error(string), Panic(uint256), or custom error
+// found in any of contractABIs. Always returns non-nil *RevertError (with raw Data only, if nothing matched).
+func DecodeRevertData(data []byte, contractABIs ...abi.ABI) *RevertError {
+	revertError := &RevertError{Data: data}
+
+	if len(data) < 4 {
+		return revertError
+	}
+
+	selector, payload := data[:4], data[4:]
+
+	switch {
+	case bytes.Equal(selector, errorStringSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			revertError.Reason = reason
+		}
+	case bytes.Equal(selector, panicSelector):
+		if len(payload) >= 32 {
+			revertError.PanicCode = new(big.Int).SetBytes(payload[:32])
+			revertError.Reason = fmt.Sprintf("unknown panic code: %#x", revertError.PanicCode)
+
+			if revertError.PanicCode.IsUint64() {
+				if reason, ok := panicReasons[revertError.PanicCode.Uint64()]; ok {
+					revertError.Reason = reason
+				}
+			}
+		}
+	default:
+		for _, contractABI := range contractABIs {
+			customError, err := contractABI.ErrorByID([4]byte(selector))
+			if err != nil {
+				continue
+			}
+
+			args, err := customError.Inputs.Unpack(payload)
+			if err != nil {
+				continue
+			}
+
+			revertError.CustomError = customError
+			revertError.CustomErrorArgs = args
+			break
+		}
+	}
+
+	return revertError
+}
+
+// revertErrorFromNodeError extracts revert data from node error (eth_call / eth_estimateGas) and decodes it.
+// Returns nil if err is not about reverted execution.
+func revertErrorFromNodeError(err error, contractABIs ...abi.ABI) *RevertError {
+	if err == nil {
+		return nil
+	}
+
+	var data []byte
+	var dataError rpc.DataError
+
+	if errors.As(err, &dataError) {
+		if hexData, ok := dataError.ErrorData().(string); ok {
+			data, _ = hexutil.Decode(hexData)
+		}
+	}
+
+	if data == nil && !strings.Contains(err.Error(), "execution reverted") {
+		return nil
+	}
+
+	revertError := DecodeRevertData(data, contractABIs...)
+	revertError.cause = err
+
+	// Some nodes return reason in message only ("execution reverted: reason"), without data
+	if data == nil {
+		if _, reason, found := strings.Cut(err.Error(), "execution reverted: "); found {
+			revertError.Reason = reason
+		}
+	}
+
+	return revertError
+}
+
+// GetRevertReason replays failed (receipt status 0) transaction as eth_call at the block it was mined in,
+// and returns decoded revert reason. Custom errors are resolved from contractABIs.
+//
+// Returns nil, nil if receipt status is successful or replay did not revert (state at the end of block can differ from
+// state at the moment of execution).
+func (eipHelper *EIP1559TransactionHelper) GetRevertReason(ctx context.Context, signedTx *types.Transaction, receipt *types.Receipt, contractABIs ...abi.ABI) (*RevertError, error) {
+	if receipt == nil || signedTx == nil {
+		return nil, fmt.Errorf("both signed transaction and receipt must be provided")
+	}
+
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, nil
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction sender: %s", err)
+	}
+
+	return eipHelper.replayCall(ctx, from, signedTx.To(), signedTx.Value(), signedTx.Data(), signedTx.Gas(), receipt.BlockNumber, contractABIs...)
+}
+
+// RevertReason - same as EIP1559TransactionHelper.GetRevertReason for this transaction (it must be mined already)
+func (pendingTx *PendingTx) RevertReason(ctx context.Context, contractABIs ...abi.ABI) (*RevertError, error) {
+	receipt := pendingTx.Receipt()
+	if receipt == nil || pendingTx.backend == nil {
+		return nil, fmt.Errorf("transaction is not mined (or was emulated)")
+	}
+
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, nil
+	}
+
+	return replayCall(ctx, pendingTx.backend, pendingTx.from, pendingTx.signedTx.To(), pendingTx.signedTx.Value(), pendingTx.signedTx.Data(), pendingTx.signedTx.Gas(), receipt.BlockNumber, contractABIs...)
+}
+
+func (eipHelper *EIP1559TransactionHelper) replayCall(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte, gas uint64, blockNumber *big.Int, contractABIs ...abi.ABI) (*RevertError, error) {
+	return replayCall(ctx, eipHelper.backend, from, to, value, data, gas, blockNumber, append(contractABIs, eipHelper.revertABIs...)...)
+}
+
+func replayCall(ctx context.Context, backend TxHelperBackend, from common.Address, to *common.Address, value *big.Int, data []byte, gas uint64, blockNumber *big.Int, contractABIs ...abi.ABI) (*RevertError, error) {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Gas:   gas,
+		Value: value,
+		Data:  data,
+	}
+
+	_, err := backend.CallContract(ctx, msg, blockNumber)
+	if err == nil {
+		return nil, nil
+	}
+
+	if revertError := revertErrorFromNodeError(err, contractABIs...); revertError != nil {
+		return revertError, nil
+	}
+
+	return nil, WrapExternalError(err, "failed to replay transaction")
+}
+
+// revertedReceiptError builds error returned along with receipt of reverted transaction (see WithRevertDecoding)
+func (eipHelper *EIP1559TransactionHelper) revertedReceiptError(ctx context.Context, signedTx *types.Transaction, receipt *types.Receipt) error {
+	errorWrapper := &ExternalErrorWrapper{
+		OurMessage:        "transaction reverted",
+		AdditionalContext: fmt.Sprintf("tx hash: %s, block: %s", receipt.TxHash.Hex(), receipt.BlockNumber),
+	}
+
+	revertError, err := eipHelper.GetRevertReason(ctx, signedTx, receipt)
+
+	switch {
+	case err != nil:
+		errorWrapper.OriginalError = err
+	case revertError != nil:
+		errorWrapper.OriginalError = revertError
+	default:
+		errorWrapper.OriginalError = &RevertError{} // Replay succeeded, so no reason is known
+	}
+
+	return errorWrapper
+}
@@ -0,0 +1,220 @@
+package goeth_tx_helper
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const testCustomErrorABI = `[{"type":"error","name":"InsufficientBalance","inputs":[
+	{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+
+// fakeDataError - node error carrying revert data, as rpc client returns it
+type fakeDataError struct {
+	message string
+	data    interface{}
+}
+
+func (e *fakeDataError) Error() string {
+	return e.message
+}
+
+func (e *fakeDataError) ErrorData() interface{} {
+	return e.data
+}
+
+func mustPackRevertData(t *testing.T, selector []byte, typeName string, value interface{}) []byte {
+	t.Helper()
+
+	argumentType, err := abi.NewType(typeName, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := abi.Arguments{{Type: argumentType}}.Pack(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append(append([]byte(nil), selector...), payload...)
+}
+
+func TestDecodeRevertData(t *testing.T) {
+	customErrorABI, err := abi.JSON(strings.NewReader(testCustomErrorABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customError := customErrorABI.Errors["InsufficientBalance"]
+
+	customErrorPayload, err := customError.Inputs.Pack(big.NewInt(10), big.NewInt(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customErrorData := append(append([]byte(nil), customError.ID[:4]...), customErrorPayload...)
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantReason      string
+		wantPanicCode   int64 // -1 - no panic code
+		wantCustomError string
+		wantMessage     string
+	}{
+		{
+			name:          "Error(string)",
+			data:          mustPackRevertData(t, errorStringSelector, "string", "not the owner"),
+			wantReason:    "not the owner",
+			wantPanicCode: -1,
+			wantMessage:   "execution reverted: not the owner",
+		},
+		{
+			name:          "known panic code",
+			data:          mustPackRevertData(t, panicSelector, "uint256", big.NewInt(0x11)),
+			wantReason:    "arithmetic underflow or overflow",
+			wantPanicCode: 0x11,
+			wantMessage:   "execution reverted: panic 0x11 (arithmetic underflow or overflow)",
+		},
+		{
+			name:          "unknown panic code",
+			data:          mustPackRevertData(t, panicSelector, "uint256", big.NewInt(0x99)),
+			wantReason:    "unknown panic code: 0x99",
+			wantPanicCode: 0x99,
+			wantMessage:   "execution reverted: panic 0x99 (unknown panic code: 0x99)",
+		},
+		{
+			name:            "custom error from ABI",
+			data:            customErrorData,
+			wantPanicCode:   -1,
+			wantCustomError: "InsufficientBalance",
+			wantMessage:     "execution reverted: InsufficientBalance[10 20]",
+		},
+		{
+			name:          "unknown selector",
+			data:          hexutil.MustDecode("0xdeadbeef01"),
+			wantPanicCode: -1,
+			wantMessage:   "execution reverted: unknown revert data 0xdeadbeef01",
+		},
+		{
+			name:          "too short",
+			data:          hexutil.MustDecode("0x0102"),
+			wantPanicCode: -1,
+			wantMessage:   "execution reverted: unknown revert data 0x0102",
+		},
+		{
+			name:          "empty",
+			data:          nil,
+			wantPanicCode: -1,
+			wantMessage:   "execution reverted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revertError := DecodeRevertData(test.data, customErrorABI)
+
+			if revertError.Reason != test.wantReason {
+				t.Errorf("reason %q, want %q", revertError.Reason, test.wantReason)
+			}
+
+			if test.wantPanicCode < 0 && revertError.PanicCode != nil {
+				t.Errorf("unexpected panic code %s", revertError.PanicCode)
+			} else if test.wantPanicCode >= 0 && (revertError.PanicCode == nil || revertError.PanicCode.Cmp(big.NewInt(test.wantPanicCode)) != 0) {
+				t.Errorf("panic code %v, want %d", revertError.PanicCode, test.wantPanicCode)
+			}
+
+			if test.wantCustomError == "" && revertError.CustomError != nil {
+				t.Errorf("unexpected custom error %s", revertError.CustomError.Name)
+			} else if test.wantCustomError != "" && (revertError.CustomError == nil || revertError.CustomError.Name != test.wantCustomError) {
+				t.Errorf("custom error %v, want %s", revertError.CustomError, test.wantCustomError)
+			}
+
+			if revertError.Error() != test.wantMessage {
+				t.Errorf("message %q, want %q", revertError.Error(), test.wantMessage)
+			}
+		})
+	}
+
+	// Without ABI custom error stays undecoded
+	if revertError := DecodeRevertData(customErrorData); revertError.CustomError != nil {
+		t.Errorf("custom error decoded without ABI")
+	}
+}
+
+func TestRevertErrorFromNodeError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantNil    bool
+		wantReason string
+		wantData   bool
+	}{
+		{
+			name:    "nil",
+			err:     nil,
+			wantNil: true,
+		},
+		{
+			name:    "not a revert",
+			err:     errors.New("insufficient funds for gas * price + value"),
+			wantNil: true,
+		},
+		{
+			name:       "revert data",
+			err:        &fakeDataError{message: "execution reverted", data: hexutil.Encode(mustPackRevertData(t, errorStringSelector, "string", "paused"))},
+			wantReason: "paused",
+			wantData:   true,
+		},
+		{
+			name:       "message only",
+			err:        errors.New("execution reverted: transfer amount exceeds balance"),
+			wantReason: "transfer amount exceeds balance",
+		},
+		{
+			name:       "revert without reason",
+			err:        errors.New("execution reverted"),
+			wantReason: "",
+		},
+		{
+			name:       "undecodable data",
+			err:        &fakeDataError{message: "execution reverted", data: "0xdeadbeef"},
+			wantReason: "",
+			wantData:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revertError := revertErrorFromNodeError(test.err)
+
+			if test.wantNil {
+				if revertError != nil {
+					t.Fatalf("expected nil, got %v", revertError)
+				}
+
+				return
+			}
+
+			if revertError == nil {
+				t.Fatal("expected revert error")
+			}
+
+			if revertError.Reason != test.wantReason {
+				t.Errorf("reason %q, want %q", revertError.Reason, test.wantReason)
+			}
+
+			if hasData := len(revertError.Data) > 0; hasData != test.wantData {
+				t.Errorf("revert data present: %t, want %t", hasData, test.wantData)
+			}
+
+			if !errors.Is(revertError, test.err) {
+				t.Errorf("original node error is not wrapped")
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// RPCExchange - one JSON-RPC request and the response node gave to it, as stored in fixture file
+type RPCExchange struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// rpcMessage - fields of JSON-RPC message needed for matching requests and responses
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// RecordingTransport is http.RoundTripper saving every JSON-RPC request and response to fixture file,
+// use it to record real session once and replay it in CI with ReplayTransport (in the same spirit as sushi_v3_receipt_examples):
+//
+//	recorder := NewRecordingTransport(nil, "testdata/send_tx.json")
+//	txHelper, err := NewEIP1559TxHelper(rpcUrl, WithHTTPClient(&http.Client{Transport: recorder}))
+//
+// Fixture file is rewritten after every exchange, so it is complete even if process is interrupted.
+type RecordingTransport struct {
+	base        http.RoundTripper
+	fixturePath string
+
+	lock      sync.Mutex
+	exchanges []RPCExchange
+}
+
+// NewRecordingTransport creates recording transport, requests are actually sent by base (http.DefaultTransport if nil)
+func NewRecordingTransport(base http.RoundTripper, fixturePath string) *RecordingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RecordingTransport{
+		base:        base,
+		fixturePath: fixturePath,
+	}
+}
+
+func (rt *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readAndRestoreBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rt.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readAndRestoreBody(&response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return response, nil // Transport-level failures are not JSON-RPC responses, nothing to record
+	}
+
+	exchanges, err := pairRPCMessages(requestBody, responseBody)
+	if err != nil {
+		return response, nil // Not a JSON-RPC exchange, pass it through
+	}
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	rt.exchanges = append(rt.exchanges, exchanges...)
+
+	if err = writeRPCFixture(rt.fixturePath, rt.exchanges); err != nil {
+		_ = response.Body.Close() // Response is not returned, so caller can't close it
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Exchanges returns all exchanges recorded so far
+func (rt *RecordingTransport) Exchanges() []RPCExchange {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	return append([]RPCExchange(nil), rt.exchanges...)
+}
+
+// ReplayTransport is http.RoundTripper serving JSON-RPC responses recorded by RecordingTransport, no network is used.
+//
+// Requests are matched by method and params (request id is ignored, response gets id of incoming request).
+// Identical requests get recorded responses in recorded order, once they are exhausted the last one is repeated
+// (so polling, like waiting for receipt, works). Unknown request gets JSON-RPC error.
+type ReplayTransport struct {
+	lock      sync.Mutex
+	responses map[string][]json.RawMessage
+	last      map[string]json.RawMessage
+}
+
+// NewReplayTransport loads fixture file written by RecordingTransport
+func NewReplayTransport(fixturePath string) (*ReplayTransport, error) {
+	fixture, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RPC fixture: %s", err)
+	}
+
+	var exchanges []RPCExchange
+	if err = json.Unmarshal(fixture, &exchanges); err != nil {
+		return nil, fmt.Errorf("failed to parse RPC fixture %s: %s", fixturePath, err)
+	}
+
+	rt := &ReplayTransport{
+		responses: make(map[string][]json.RawMessage),
+		last:      make(map[string]json.RawMessage),
+	}
+
+	for _, exchange := range exchanges {
+		var message rpcMessage
+		if err = json.Unmarshal(exchange.Request, &message); err != nil {
+			return nil, fmt.Errorf("failed to parse request in RPC fixture %s: %s", fixturePath, err)
+		}
+
+		key := rpcMessageKey(message)
+		rt.responses[key] = append(rt.responses[key], exchange.Response)
+	}
+
+	return rt, nil
+}
+
+func (rt *ReplayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readAndRestoreBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, isBatch, err := splitRPCMessages(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("replay: request is not JSON-RPC: %s", err)
+	}
+
+	replies := make([]json.RawMessage, 0, len(messages))
+
+	for _, raw := range messages {
+		var message rpcMessage
+		if err = json.Unmarshal(raw, &message); err != nil {
+			return nil, fmt.Errorf("replay: request is not JSON-RPC: %s", err)
+		}
+
+		reply, err := withRPCID(rt.nextResponse(message), message.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		replies = append(replies, reply)
+	}
+
+	var responseBody []byte
+	if isBatch {
+		responseBody, err = json.Marshal(replies)
+	} else {
+		responseBody, err = json.Marshal(replies[0])
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       request,
+	}, nil
+}
+
+func (rt *ReplayTransport) nextResponse(message rpcMessage) json.RawMessage {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	key := rpcMessageKey(message)
+
+	if queue := rt.responses[key]; len(queue) > 0 {
+		rt.responses[key] = queue[1:]
+		rt.last[key] = queue[0]
+
+		return queue[0]
+	}
+
+	if last, ok := rt.last[key]; ok {
+		return last
+	}
+
+	notFound, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    -32000,
+			"message": fmt.Sprintf("replay: no recorded response for %s %s", message.Method, message.Params),
+		},
+	})
+
+	return notFound
+}
+
+// rpcMessageKey - method + params in canonical form (keys of objects sorted), so formatting does not affect matching
+func rpcMessageKey(message rpcMessage) string {
+	var params interface{}
+	if len(message.Params) > 0 && json.Unmarshal(message.Params, &params) == nil {
+		if canonical, err := json.Marshal(params); err == nil {
+			return message.Method + ":" + string(canonical)
+		}
+	}
+
+	return message.Method + ":" + string(message.Params)
+}
+
+// pairRPCMessages matches requests and responses (single or batch) by id
+func pairRPCMessages(requestBody []byte, responseBody []byte) ([]RPCExchange, error) {
+	requests, _, err := splitRPCMessages(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, _, err := splitRPCMessages(responseBody)
+	if err != nil {
+		return nil, err
+	}
+
+	responsesByID := make(map[string]json.RawMessage, len(responses))
+	for _, raw := range responses {
+		var message rpcMessage
+		if err = json.Unmarshal(raw, &message); err != nil {
+			return nil, err
+		}
+
+		responsesByID[string(message.ID)] = raw
+	}
+
+	exchanges := make([]RPCExchange, 0, len(requests))
+	for _, raw := range requests {
+		var message rpcMessage
+		if err = json.Unmarshal(raw, &message); err != nil {
+			return nil, err
+		}
+
+		if response, ok := responsesByID[string(message.ID)]; ok {
+			exchanges = append(exchanges, RPCExchange{Request: raw, Response: response})
+		}
+	}
+
+	return exchanges, nil
+}
+
+// splitRPCMessages returns messages of batch (JSON array) or single message
+func splitRPCMessages(body []byte) ([]json.RawMessage, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var messages []json.RawMessage
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return nil, true, err
+		}
+
+		return messages, true, nil
+	}
+
+	if !json.Valid(trimmed) {
+		return nil, false, fmt.Errorf("invalid JSON")
+	}
+
+	return []json.RawMessage{trimmed}, false, nil
+}
+
+// withRPCID returns copy of response with "id" replaced
+func withRPCID(response json.RawMessage, id json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil, fmt.Errorf("replay: recorded response is not JSON-RPC: %s", err)
+	}
+
+	if len(id) > 0 {
+		fields["id"] = id
+	}
+
+	return json.Marshal(fields)
+}
+
+func readAndRestoreBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+
+	content, err := io.ReadAll(*body)
+	_ = (*body).Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(content))
+
+	return content, nil
+}
+
+func writeRPCFixture(fixturePath string, exchanges []RPCExchange) error {
+	fixture, err := json.MarshalIndent(exchanges, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode RPC fixture: %s", err)
+	}
+
+	if err = os.WriteFile(fixturePath, fixture, 0o644); err != nil {
+		return fmt.Errorf("failed to write RPC fixture: %s", err)
+	}
+
+	return nil
+}
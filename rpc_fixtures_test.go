@@ -0,0 +1,229 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newCountingRPCServer - node answering every eth_blockNumber with the next number, eth_chainId with 0x539
+func newCountingRPCServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var blockNumber atomic.Uint64
+
+	answer := func(raw json.RawMessage) map[string]interface{} {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.Unmarshal(raw, &request)
+
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+
+		switch request.Method {
+		case "eth_blockNumber":
+			response["result"] = hexutil.Uint64(blockNumber.Add(1))
+		case "eth_chainId":
+			response["result"] = "0x539"
+		default:
+			response["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+
+		return response
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+			var batch []json.RawMessage
+			_ = json.Unmarshal(body, &batch)
+
+			responses := make([]map[string]interface{}, 0, len(batch))
+			for _, raw := range batch {
+				responses = append(responses, answer(raw))
+			}
+
+			_ = json.NewEncoder(w).Encode(responses)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(answer(body))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func dialWithTransport(t *testing.T, url string, transport http.RoundTripper) *rpc.Client {
+	t.Helper()
+
+	client, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func blockNumberBatch() []rpc.BatchElem {
+	return []rpc.BatchElem{
+		{Method: "eth_chainId", Result: new(hexutil.Uint64)},
+		{Method: "eth_blockNumber", Result: new(hexutil.Uint64)},
+	}
+}
+
+func TestRecordAndReplayRPCSession(t *testing.T) {
+	ctx := context.Background()
+	server := newCountingRPCServer(t)
+	fixturePath := filepath.Join(t.TempDir(), "session.json")
+
+	// Record: two single calls and one batch
+	recorder := NewRecordingTransport(nil, fixturePath)
+	client := dialWithTransport(t, server.URL, recorder)
+
+	var recorded []hexutil.Uint64
+	for i := 0; i < 2; i++ {
+		var blockNumber hexutil.Uint64
+		if err := client.CallContext(ctx, &blockNumber, "eth_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+		recorded = append(recorded, blockNumber)
+	}
+
+	batch := blockNumberBatch()
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	recorded = append(recorded, *batch[1].Result.(*hexutil.Uint64))
+
+	if len(recorder.Exchanges()) != 4 {
+		t.Fatalf("expected 4 recorded exchanges (batch is split), got %d", len(recorder.Exchanges()))
+	}
+
+	server.Close() // Replay must not need the node
+
+	// Replay with fresh client
+	replayer, err := NewReplayTransport(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayClient := dialWithTransport(t, server.URL, replayer)
+
+	// eth_chainId was recorded inside the batch, now it goes first - its request id differs from the recorded one
+	var chainID hexutil.Uint64
+	if err = replayClient.CallContext(ctx, &chainID, "eth_chainId"); err != nil || chainID != 0x539 {
+		t.Fatalf("expected replayed chain id 0x539, got %d (%v)", chainID, err)
+	}
+
+	var replayed []hexutil.Uint64
+	for i := 0; i < 3; i++ {
+		var blockNumber hexutil.Uint64
+		if err = replayClient.CallContext(ctx, &blockNumber, "eth_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+		replayed = append(replayed, blockNumber)
+	}
+
+	// Responses to identical requests come in recorded order, the last one is repeated when they are exhausted
+	if fmt.Sprint(replayed) != fmt.Sprint(recorded) {
+		t.Fatalf("expected replayed block numbers %v, got %v", recorded, replayed)
+	}
+
+	batch = blockNumberBatch()
+	if err = replayClient.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, elem := range batch {
+		if elem.Error != nil {
+			t.Fatalf("batch element %s failed: %s", elem.Method, elem.Error)
+		}
+	}
+
+	if *batch[0].Result.(*hexutil.Uint64) != 0x539 || *batch[1].Result.(*hexutil.Uint64) != recorded[len(recorded)-1] {
+		t.Fatalf("unexpected replayed batch results: %d, %d", *batch[0].Result.(*hexutil.Uint64), *batch[1].Result.(*hexutil.Uint64))
+	}
+
+	// Request never recorded gets JSON-RPC error
+	var gasPrice hexutil.Big
+	if err = replayClient.CallContext(ctx, &gasPrice, "eth_gasPrice"); err == nil {
+		t.Fatal("expected error for request missing in fixture")
+	}
+}
+
+func TestReplayTransportRewritesResponseID(t *testing.T) {
+	fixturePath := filepath.Join(t.TempDir(), "session.json")
+
+	err := writeRPCFixture(fixturePath, []RPCExchange{{
+		Request:  json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`),
+		Response: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":"0x539"}`),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayTransport(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "http://replay", bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":77,"method":"eth_chainId","params":[]}`)))
+
+	response, err := replayer.RoundTrip(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reply struct {
+		ID     int    `json:"id"`
+		Result string `json:"result"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+
+	if reply.ID != 77 || reply.Result != "0x539" {
+		t.Fatalf("expected id 77 and result 0x539, got %+v", reply)
+	}
+}
+
+func TestRecordingTransportFixtureWriteError(t *testing.T) {
+	server := newCountingRPCServer(t)
+
+	// Directory of fixture does not exist, so it can't be written
+	recorder := NewRecordingTransport(nil, filepath.Join(t.TempDir(), "missing", "session.json"))
+
+	request, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := recorder.RoundTrip(request)
+	if err == nil {
+		_ = response.Body.Close()
+		t.Fatal("expected error for fixture which can't be written")
+	}
+
+	if response != nil {
+		t.Fatal("response must not be returned along with error")
+	}
+}
@@ -0,0 +1,120 @@
+package goeth_tx_helper
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// Signer signs transactions on behalf of one address. Key material doesn't have to live in process memory:
+// implementations can delegate signing to keystore, external process, hardware or cloud KMS.
+type Signer interface {
+	Address() common.Address
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// PrivateKeySigner signs with in-memory private key
+type PrivateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewPrivateKeySigner(privateKey *ecdsa.PrivateKey) (*PrivateKeySigner, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("private key must not be nil")
+	}
+
+	address, err := GetPublicAddressFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKeySigner{
+		privateKey: privateKey,
+		address:    address,
+	}, nil
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *PrivateKeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.privateKey)
+}
+
+// KeystoreSigner signs with account stored in go-ethereum keystore (encrypted key files)
+type KeystoreSigner struct {
+	keystore   *keystore.KeyStore
+	account    accounts.Account
+	passphrase string
+}
+
+// NewKeystoreSigner creates signer for keystore account. If passphrase is empty, account must be unlocked in keystore
+// (see keystore.KeyStore.Unlock / TimedUnlock), otherwise key is decrypted with passphrase for every signature.
+func NewKeystoreSigner(ks *keystore.KeyStore, account accounts.Account, passphrase string) (*KeystoreSigner, error) {
+	if ks == nil {
+		return nil, fmt.Errorf("keystore must not be nil")
+	}
+
+	if !ks.HasAddress(account.Address) {
+		return nil, fmt.Errorf("account %s not found in keystore", account.Address.Hex())
+	}
+
+	return &KeystoreSigner{
+		keystore:   ks,
+		account:    account,
+		passphrase: passphrase,
+	}, nil
+}
+
+func (s *KeystoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *KeystoreSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if s.passphrase == "" {
+		return s.keystore.SignTx(s.account, tx, chainID)
+	}
+
+	return s.keystore.SignTxWithPassphrase(s.account, s.passphrase, tx, chainID)
+}
+
+// BindSigner adapts bind.SignerFn (e.g. from bind.TransactOpts) to Signer. Chain ID is defined by signerFn itself.
+type BindSigner struct {
+	address  common.Address
+	signerFn bind.SignerFn
+}
+
+func NewBindSigner(address common.Address, signerFn bind.SignerFn) (*BindSigner, error) {
+	if signerFn == nil {
+		return nil, fmt.Errorf("signer function must not be nil")
+	}
+
+	return &BindSigner{
+		address:  address,
+		signerFn: signerFn,
+	}, nil
+}
+
+// NewBindSignerFromTransactOpts creates signer from bind.TransactOpts (From + Signer), e.g. made by bind.NewKeyedTransactorWithChainID
+func NewBindSignerFromTransactOpts(opts *bind.TransactOpts) (*BindSigner, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("transact opts must not be nil")
+	}
+
+	return NewBindSigner(opts.From, opts.Signer)
+}
+
+func (s *BindSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *BindSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.signerFn(s.address, tx)
+}
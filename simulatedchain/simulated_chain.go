@@ -0,0 +1,145 @@
+// Package simulatedchain runs goeth_tx_helper on go-ethereum's in-process simulated chain, for end-to-end tests
+// without network. It lives in a separate package, so the library itself does not depend on the whole geth node.
+package simulatedchain
+
+import (
+	"context"
+	"fmt"
+	goeth_tx_helper "github.com/anxp/goeth-tx-helper"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+// faucetBalance - balance of faucet account in genesis, 1_000_000 ETH
+var faucetBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1_000_000_000_000_000_000))
+
+// Chain runs helper on go-ethereum's in-process simulated chain (ethclient/simulated): contracts deployed
+// in tests actually execute, SendTransaction, ContractFunctionCall, FilterTransactionLog work end to end with no network.
+//
+// By default every sent transaction is mined immediately (auto-commit), so SendTransaction returns receipt at once.
+// With auto-commit disabled blocks are produced only by Commit.
+type Chain struct {
+	backend    *simulated.Backend
+	helper     *goeth_tx_helper.EIP1559TransactionHelper
+	faucet     *goeth_tx_helper.PrivateKeySigner
+	chainID    *big.Int
+	autoCommit atomic.Bool
+}
+
+// chainClient - backend of helper, commits block right after every sent transaction if auto-commit is enabled
+type chainClient struct {
+	simulated.Client
+	chain *Chain
+}
+
+func (client *chainClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := client.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+
+	if client.chain.autoCommit.Load() {
+		client.chain.backend.Commit()
+	}
+
+	return nil
+}
+
+// New starts simulated chain with given genesis allocation (can be nil) and creates helper working on it.
+// Besides alloc, genesis contains faucet account (see Faucet, Fund) with 1_000_000 ETH.
+func New(alloc types.GenesisAlloc, opts ...goeth_tx_helper.TxHelperOption) (*Chain, error) {
+	faucetKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate faucet key: %s", err)
+	}
+
+	faucet, err := goeth_tx_helper.NewPrivateKeySigner(faucetKey)
+	if err != nil {
+		return nil, err
+	}
+
+	genesisAlloc := types.GenesisAlloc{
+		faucet.Address(): {Balance: faucetBalance},
+	}
+
+	for address, account := range alloc {
+		genesisAlloc[address] = account
+	}
+
+	chain := &Chain{
+		backend: simulated.NewBackend(genesisAlloc),
+		faucet:  faucet,
+	}
+	chain.autoCommit.Store(true)
+
+	chainID, err := chain.backend.Client().ChainID(context.Background())
+	if err != nil {
+		_ = chain.backend.Close()
+		return nil, goeth_tx_helper.WrapExternalError(err, "failed to get chain ID of simulated chain")
+	}
+
+	chain.chainID = chainID
+
+	chain.helper, err = goeth_tx_helper.NewEIP1559TxHelperWithBackend(&chainClient{Client: chain.backend.Client(), chain: chain}, opts...)
+	if err != nil {
+		_ = chain.backend.Close()
+		return nil, err
+	}
+
+	return chain, nil
+}
+
+// Helper returns helper working on the simulated chain
+func (chain *Chain) Helper() *goeth_tx_helper.EIP1559TransactionHelper {
+	return chain.helper
+}
+
+// Backend returns underlying simulated backend (for Rollback, Fork, etc.)
+func (chain *Chain) Backend() *simulated.Backend {
+	return chain.backend
+}
+
+// ChainID returns chain ID of simulated chain (1337)
+func (chain *Chain) ChainID() *big.Int {
+	return new(big.Int).Set(chain.chainID)
+}
+
+// Faucet returns signer of the account funded in genesis
+func (chain *Chain) Faucet() goeth_tx_helper.Signer {
+	return chain.faucet
+}
+
+// SetAutoCommit enables/disables mining a block right after every sent transaction
+func (chain *Chain) SetAutoCommit(autoCommit bool) {
+	chain.autoCommit.Store(autoCommit)
+}
+
+// Commit mines block with all pending transactions and returns its hash
+func (chain *Chain) Commit() common.Hash {
+	return chain.backend.Commit()
+}
+
+// AdjustTime shifts time of the next block (and commits block)
+func (chain *Chain) AdjustTime(adjustment time.Duration) error {
+	return chain.backend.AdjustTime(adjustment)
+}
+
+// Fund sends amount (wei) from faucet to address and waits for it to be mined (so auto-commit must be enabled,
+// or Commit must be called from another goroutine)
+func (chain *Chain) Fund(ctx context.Context, address common.Address, amount *big.Int) (*types.Receipt, error) {
+	gasParams, err := chain.helper.GetGasParametersWithContext(ctx, chain.faucet.Address(), &address, amount, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain.helper.SendTransactionWithSigner(ctx, chain.faucet, &address, chain.chainID, gasParams, amount, nil)
+}
+
+// Close stops simulated chain
+func (chain *Chain) Close() error {
+	return chain.backend.Close()
+}
@@ -0,0 +1,118 @@
+package simulatedchain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testContractCode - runtime code of contract which, whatever is called, emits LOG1 (topic 0x01, data 42) and returns 42:
+//
+//	PUSH1 42 PUSH1 0 MSTORE, PUSH1 1 PUSH1 32 PUSH1 0 LOG1, PUSH1 32 PUSH1 0 RETURN
+var testContractCode = hexutil.MustDecode("0x602a600052600160206000a160206000f3")
+
+const testContractABI = `[{"type":"function","name":"answer","stateMutability":"nonpayable","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+
+func TestChainEndToEnd(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	contract := common.HexToAddress("0x00000000000000000000000000000000000c0de0")
+
+	chain, err := New(types.GenesisAlloc{contract: {Code: testContractCode, Balance: big.NewInt(0)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer chain.Close()
+
+	if chain.ChainID().Cmp(big.NewInt(1337)) != 0 {
+		t.Fatalf("chain ID %s, want 1337", chain.ChainID())
+	}
+
+	// Fund
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	amount := big.NewInt(1_000_000_000_000_000_000)
+
+	receipt, err := chain.Fund(ctx, recipient, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("funding transaction failed")
+	}
+
+	balance, err := chain.Backend().Client().BalanceAt(ctx, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Cmp(amount) != 0 {
+		t.Fatalf("balance %s, want %s", balance, amount)
+	}
+
+	// ContractFunctionCall
+	contractABI, err := abi.JSON(strings.NewReader(testContractABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := chain.Helper().ContractFunctionCallWithContext(ctx, &contract, contractABI, nil, "answer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].(*big.Int).Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("unexpected call results %v", results)
+	}
+
+	// SendTransaction to contract, then FilterTransactionLog on its receipt
+	data, err := contractABI.Pack("answer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gasParams, err := chain.Helper().GetGasParametersWithContext(ctx, chain.Faucet().Address(), &contract, big.NewInt(0), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err = chain.Helper().SendTransactionWithSigner(ctx, chain.Faucet(), &contract, chain.ChainID(), gasParams, big.NewInt(0), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("contract call transaction failed")
+	}
+
+	logs, err := chain.Helper().FilterTransactionLog(receipt, nil, ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		Topics:    [][]common.Hash{{common.BigToHash(big.NewInt(1))}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logs) != 1 || new(big.Int).SetBytes(logs[0].Data).Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("expected one log with data 42, got %v", logs)
+	}
+
+	logs, err = chain.Helper().FilterTransactionLog(receipt, nil, ethereum.FilterQuery{Addresses: []common.Address{recipient}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logs) != 0 {
+		t.Fatalf("expected no logs of other address, got %v", logs)
+	}
+}
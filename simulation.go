@@ -0,0 +1,43 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// SimulateTransaction executes exactly the transaction which would be sent (from, to, value, data, gas) as eth_call
+// at the pending block, nothing is broadcast. Returns data returned by called contract.
+//
+// If execution reverts, returned error wraps decoded *RevertError (use errors.As), custom errors are resolved
+// from ABIs given in WithRevertDecoding option.
+func (eipHelper *EIP1559TransactionHelper) SimulateTransaction(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte, gas uint64) ([]byte, error) {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Gas:   gas,
+		Value: value,
+		Data:  data,
+	}
+
+	var result []byte
+	var err error
+
+	// Pending block is used if backend supports it (ethclient does), otherwise - latest block
+	if pendingCaller, ok := eipHelper.backend.(ethereum.PendingContractCaller); ok {
+		result, err = pendingCaller.PendingCallContract(ctx, msg)
+	} else {
+		result, err = eipHelper.backend.CallContract(ctx, msg, nil)
+	}
+
+	if err != nil {
+		if revertError := revertErrorFromNodeError(err, eipHelper.revertABIs...); revertError != nil {
+			return nil, WrapExternalError(revertError, "transaction simulation reverted")
+		}
+
+		return nil, WrapExternalError(err, "failed to simulate transaction")
+	}
+
+	return result, nil
+}
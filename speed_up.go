@@ -0,0 +1,200 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"time"
+)
+
+// MinReplacementBumpPercent - nodes (geth txpool) accept replacement transaction (same nonce) only if both
+// maxPriorityFeePerGas and maxFeePerGas are raised at least by this percent, otherwise "replacement transaction underpriced"
+const MinReplacementBumpPercent int64 = 10
+
+// SpeedUpPolicy describes how SendTransaction bumps fees of transaction which is not mined in time
+type SpeedUpPolicy struct {
+	Timeout      time.Duration // How long to wait for transaction (and every replacement) to be mined before bumping fees
+	BumpPercent  int64         // How much fees are raised on every bump, values below MinReplacementBumpPercent are raised to it
+	MaxGasFeeCap *big.Int      // Ceiling for maxFeePerGas, fees are never raised above it. nil means no ceiling
+	MaxAttempts  int           // Max number of replacements, 0 means no limit (until MaxGasFeeCap is reached)
+}
+
+// waitMinedWithSpeedUp waits for signedTx to be mined, replacing it with higher-fee transaction (same nonce) every policy.Timeout.
+// Returns receipt of whichever transaction (original or one of replacements) actually got mined.
+// Every replacement is bumped from the previous attempt, even if node rejected it, so rejected fees are never repeated.
+func (eipHelper *EIP1559TransactionHelper) waitMinedWithSpeedUp(ctx context.Context, signer Signer, signedTx *types.Transaction) (*types.Receipt, error) {
+	policy := eipHelper.speedUpPolicy
+
+	txHashes := []common.Hash{signedTx.Hash()}
+	lastTx := signedTx // The last attempted transaction (sent or rejected), fees are bumped from it
+	attempts := 0
+	canBump := true
+
+	var lastReplacementErr error
+
+	for {
+		waitCtx, cancel := ctx, context.CancelFunc(func() {})
+		if canBump && policy.Timeout > 0 {
+			waitCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		}
+
+		receipt, err := waitAnyMined(waitCtx, eipHelper.backend, txHashes)
+		cancel()
+
+		if err == nil {
+			return receipt, nil
+		}
+
+		if ctx.Err() != nil {
+			additionalContext := fmt.Sprintf("nonce: %d, sent transactions (original and replacements): %v", signedTx.Nonce(), txHashes)
+			if lastReplacementErr != nil {
+				additionalContext += fmt.Sprintf(", last rejected replacement: %s", lastReplacementErr)
+			}
+
+			return nil, &ExternalErrorWrapper{
+				OriginalError:     ctx.Err(),
+				OurMessage:        "transaction probably has not been mined (timeout?)",
+				AdditionalContext: additionalContext,
+			}
+		}
+
+		gasParams, ok, err := eipHelper.bumpedGasParams(ctx, lastTx, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			canBump = false // Ceiling reached, now just wait for one of already sent transactions
+			continue
+		}
+
+		replacementTx, err := signer.SignTx(
+			buildTransaction(lastTx.ChainId(), lastTx.Nonce(), gasParams, lastTx.To(), lastTx.Value(), lastTx.Data()),
+			lastTx.ChainId(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign replacement transaction: %s", err)
+		}
+
+		// If replacement is rejected (e.g. "nonce too low" because one of previous transactions has just been mined,
+		// or "replacement transaction underpriced"), we keep waiting for already sent transactions
+		if err = eipHelper.backend.SendTransaction(ctx, replacementTx); err == nil {
+			txHashes = append(txHashes, replacementTx.Hash())
+		} else {
+			lastReplacementErr = err
+			logf("replacement of transaction with nonce %d rejected: %s", replacementTx.Nonce(), err)
+		}
+
+		lastTx = replacementTx
+
+		attempts++
+		if policy.MaxAttempts > 0 && attempts >= policy.MaxAttempts {
+			canBump = false
+		}
+	}
+}
+
+// bumpedGasParams calculates fees for replacement of tx: both tip and fee cap raised at least by policy.BumpPercent,
+// fee cap is also kept above current market (2*baseFee + tip, or predicted base fee with headroom, see WithBaseFeeHeadroom). Returns false if fees cannot be raised enough because of ceiling.
+func (eipHelper *EIP1559TransactionHelper) bumpedGasParams(ctx context.Context, tx *types.Transaction, policy *SpeedUpPolicy) (Gas1559Params, bool, error) {
+	bumpPercent := policy.BumpPercent
+	if bumpPercent < MinReplacementBumpPercent {
+		bumpPercent = MinReplacementBumpPercent
+	}
+
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		return bumpedGasPriceParams(tx, bumpPercent, policy)
+	}
+
+	gasTipCap := bumpByPercent(tx.GasTipCap(), bumpPercent)
+	gasFeeCap := bumpByPercent(tx.GasFeeCap(), bumpPercent)
+
+	header, err := eipHelper.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Gas1559Params{}, false, WrapExternalError(err, "failed to request last block header")
+	}
+
+	if header.BaseFee != nil {
+		if marketFeeCap := eipHelper.gasFeeCap(header, gasTipCap); marketFeeCap.Cmp(gasFeeCap) > 0 {
+			gasFeeCap = marketFeeCap
+		}
+	}
+
+	if policy.MaxGasFeeCap != nil && gasFeeCap.Cmp(policy.MaxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(policy.MaxGasFeeCap)
+
+		// Fee cap limited by ceiling is still acceptable only if it is raised enough
+		if gasFeeCap.Cmp(bumpByPercent(tx.GasFeeCap(), bumpPercent)) < 0 {
+			return Gas1559Params{}, false, nil
+		}
+	}
+
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		return Gas1559Params{}, false, nil // Tip can't be greater than fee cap
+	}
+
+	return Gas1559Params{
+		TxType:     TxTypeDynamicFee,
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Gas:        tx.Gas(),
+		AccessList: tx.AccessList(),
+	}, true, nil
+}
+
+// bumpedGasPriceParams - same as bumpedGasParams, for legacy and access list transactions (they have gas price only)
+func bumpedGasPriceParams(tx *types.Transaction, bumpPercent int64, policy *SpeedUpPolicy) (Gas1559Params, bool, error) {
+	gasPrice := bumpByPercent(tx.GasPrice(), bumpPercent)
+
+	if policy.MaxGasFeeCap != nil && gasPrice.Cmp(policy.MaxGasFeeCap) > 0 {
+		return Gas1559Params{}, false, nil
+	}
+
+	gasParams := gasParamsFromTx(tx)
+	gasParams.GasPrice = gasPrice
+	gasParams.GasTipCap = gasPrice
+	gasParams.GasFeeCap = gasPrice
+
+	return gasParams, true, nil
+}
+
+// bumpByPercent returns value * (100 + percent) / 100, but at least value + 1
+func bumpByPercent(value *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(value, big.NewInt(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+
+	if minimal := new(big.Int).Add(value, big.NewInt(1)); bumped.Cmp(minimal) < 0 {
+		return minimal
+	}
+
+	return bumped
+}
+
+// waitAnyMined waits until any of given transactions is mined (all of them should have the same nonce, so only one can be mined)
+func waitAnyMined(ctx context.Context, backend TxHelperBackend, txHashes []common.Hash) (*types.Receipt, error) {
+	queryTicker := time.NewTicker(receiptPollInterval)
+	defer queryTicker.Stop()
+
+	for {
+		for _, txHash := range txHashes {
+			receipt, err := backend.TransactionReceipt(ctx, txHash)
+			if err == nil {
+				return receipt, nil
+			}
+
+			if !errors.Is(err, ethereum.NotFound) && ctx.Err() != nil {
+				return nil, err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-queryTicker.C:
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeSpeedUpBackend - fakeDryRunBackend which records sent replacements, rejects them while sendErr is set,
+// and mines the first accepted transaction satisfying mined
+type fakeSpeedUpBackend struct {
+	fakeDryRunBackend
+
+	sendErr error
+	mined   func(tx *types.Transaction) bool
+
+	lock     sync.Mutex
+	attempts []*types.Transaction // Every replacement helper tried to send, accepted or not
+	accepted map[common.Hash]*types.Transaction
+}
+
+func newFakeSpeedUpBackend(baseFee int64) *fakeSpeedUpBackend {
+	return &fakeSpeedUpBackend{
+		fakeDryRunBackend: fakeDryRunBackend{baseFee: big.NewInt(baseFee)},
+		accepted:          make(map[common.Hash]*types.Transaction),
+	}
+}
+
+func (backend *fakeSpeedUpBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	backend.attempts = append(backend.attempts, tx)
+
+	if backend.sendErr != nil {
+		return backend.sendErr
+	}
+
+	backend.accepted[tx.Hash()] = tx
+
+	return nil
+}
+
+func (backend *fakeSpeedUpBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	if tx, ok := backend.accepted[txHash]; ok && backend.mined != nil && backend.mined(tx) {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+	}
+
+	return nil, ethereum.NotFound
+}
+
+func (backend *fakeSpeedUpBackend) sentAttempts() []*types.Transaction {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	return append([]*types.Transaction(nil), backend.attempts...)
+}
+
+func TestBumpedGasParams(t *testing.T) {
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(100), Gas: 21000})
+	legacyTx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100), Gas: 21000})
+
+	tests := []struct {
+		name       string
+		tx         *types.Transaction
+		baseFee    int64
+		policy     SpeedUpPolicy
+		wantOk     bool
+		wantTipCap int64
+		wantFeeCap int64
+	}{
+		{
+			name:       "minimal bump",
+			tx:         dynamicTx,
+			baseFee:    30,
+			policy:     SpeedUpPolicy{BumpPercent: 1}, // Raised to MinReplacementBumpPercent
+			wantOk:     true,
+			wantTipCap: 11,
+			wantFeeCap: 110,
+		},
+		{
+			name:       "custom bump",
+			tx:         dynamicTx,
+			baseFee:    30,
+			policy:     SpeedUpPolicy{BumpPercent: 25},
+			wantOk:     true,
+			wantTipCap: 12,
+			wantFeeCap: 125,
+		},
+		{
+			name:       "fee cap follows market",
+			tx:         dynamicTx,
+			baseFee:    100,
+			policy:     SpeedUpPolicy{},
+			wantOk:     true,
+			wantTipCap: 11,
+			wantFeeCap: 211, // 2*baseFee + tip
+		},
+		{
+			name:       "market fee cap limited by ceiling",
+			tx:         dynamicTx,
+			baseFee:    100,
+			policy:     SpeedUpPolicy{MaxGasFeeCap: big.NewInt(150)},
+			wantOk:     true,
+			wantTipCap: 11,
+			wantFeeCap: 150,
+		},
+		{
+			name:    "ceiling below minimal bump",
+			tx:      dynamicTx,
+			baseFee: 30,
+			policy:  SpeedUpPolicy{MaxGasFeeCap: big.NewInt(105)},
+			wantOk:  false,
+		},
+		{
+			name:       "legacy",
+			tx:         legacyTx,
+			baseFee:    30,
+			policy:     SpeedUpPolicy{},
+			wantOk:     true,
+			wantTipCap: 110,
+			wantFeeCap: 110,
+		},
+		{
+			name:    "legacy ceiling",
+			tx:      legacyTx,
+			baseFee: 30,
+			policy:  SpeedUpPolicy{MaxGasFeeCap: big.NewInt(105)},
+			wantOk:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txHelper, err := NewEIP1559TxHelperWithBackend(newFakeSpeedUpBackend(test.baseFee))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gasParams, ok, err := txHelper.bumpedGasParams(context.Background(), test.tx, &test.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok != test.wantOk {
+				t.Fatalf("ok %t, want %t", ok, test.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if gasParams.GasTipCap.Cmp(big.NewInt(test.wantTipCap)) != 0 || gasParams.GasFeeCap.Cmp(big.NewInt(test.wantFeeCap)) != 0 {
+				t.Fatalf("tip cap %s, fee cap %s, want %d and %d", gasParams.GasTipCap, gasParams.GasFeeCap, test.wantTipCap, test.wantFeeCap)
+			}
+
+			if gasParams.Gas != test.tx.Gas() {
+				t.Fatalf("gas %d, want %d", gasParams.Gas, test.tx.Gas())
+			}
+		})
+	}
+}
+
+func TestWaitMinedWithSpeedUp(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chainID := big.NewInt(1337)
+
+	signOriginal := func(t *testing.T) *types.Transaction {
+		signedTx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(100), Gas: 21000, To: &to,
+		}), chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return signedTx
+	}
+
+	t.Run("replacement mined", func(t *testing.T) {
+		backend := newFakeSpeedUpBackend(30)
+		backend.mined = func(tx *types.Transaction) bool { return tx.GasTipCap().Cmp(big.NewInt(13)) >= 0 }
+
+		txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithSpeedUpPolicy(&SpeedUpPolicy{Timeout: 10 * time.Millisecond}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		receipt, err := txHelper.waitMinedWithSpeedUp(context.Background(), signer, signOriginal(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Tips: 10 -> 11 -> 12 -> 13
+		attempts := backend.sentAttempts()
+		if len(attempts) != 3 || receipt.TxHash != attempts[2].Hash() {
+			t.Fatalf("expected receipt of the third replacement, got %d replacements", len(attempts))
+		}
+	})
+
+	t.Run("rejected replacements are bumped from the last attempt", func(t *testing.T) {
+		backend := newFakeSpeedUpBackend(30)
+		backend.sendErr = errors.New("replacement transaction underpriced")
+
+		txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithSpeedUpPolicy(&SpeedUpPolicy{Timeout: 10 * time.Millisecond, MaxAttempts: 3}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		_, err = txHelper.waitMinedWithSpeedUp(ctx, signer, signOriginal(t))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "replacement transaction underpriced") {
+			t.Fatalf("expected last replacement error in %q", err)
+		}
+
+		attempts := backend.sentAttempts()
+		if len(attempts) != 3 {
+			t.Fatalf("expected MaxAttempts (3) replacements, got %d", len(attempts))
+		}
+
+		wantTips := []int64{11, 12, 13}
+		for i, attempt := range attempts {
+			if attempt.GasTipCap().Cmp(big.NewInt(wantTips[i])) != 0 {
+				t.Fatalf("replacement %d has tip %s, want %d", i, attempt.GasTipCap(), wantTips[i])
+			}
+		}
+	})
+
+	t.Run("ceiling stops bumping", func(t *testing.T) {
+		backend := newFakeSpeedUpBackend(30)
+
+		// Fee caps: 100 -> 110 -> 121, the next one (133) is above ceiling
+		txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithSpeedUpPolicy(&SpeedUpPolicy{Timeout: 10 * time.Millisecond, MaxGasFeeCap: big.NewInt(130)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		if _, err = txHelper.waitMinedWithSpeedUp(ctx, signer, signOriginal(t)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+
+		if attempts := backend.sentAttempts(); len(attempts) != 2 {
+			t.Fatalf("expected 2 replacements below ceiling, got %d", len(attempts))
+		}
+	})
+}
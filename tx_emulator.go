@@ -0,0 +1,335 @@
+package goeth_tx_helper
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sync"
+)
+
+// TxEmulator is a programmable replacement of sending in emulation mode (see WithEmulator).
+// It also serves read paths (ContractFunctionCall, GetBaseFee, GetLatestBlockNumber, GetGasParameters), so tests can run fully offline.
+// Read paths which are not mocked (see MockCall, SetBaseFee, SetBlockNumber) go to node, as without emulation.
+//
+// Responses are chosen by rules, matched by to-address and 4-byte method selector (see On), every rule holds a queue
+// of receipts / errors. If no rule matches (or matched rules are exhausted), default receipt is returned.
+// Every would-be transaction is recorded, so tests can assert on what code tried to send (see SentTransactions).
+//
+// Receipt fixtures from sushi_v3_receipt_examples package can be passed directly:
+//
+//	emulator := NewTxEmulator(types.Receipt{Status: 1})
+//	emulator.On(&positionManager, selector).ReturnReceipt(sushi_v3_receipt_examples.CloseETHUSDCReceiptExample)
+type TxEmulator struct {
+	lock           sync.Mutex
+	defaultReceipt types.Receipt
+	rules          []*EmulatorRule
+	sent           []EmulatedTx
+
+	// Read paths, see MockCall, SetBaseFee, SetBlockNumber, SetGasLimit
+	calls       map[emulatorCallKey]emulatorCallResponse
+	baseFee     *big.Int // nil - not set, node is asked
+	blockNumber *uint64  // nil - not set, node is asked
+	gasLimit    uint64
+}
+
+// EmulatorRule matches would-be transactions and returns queued responses, one per matched transaction
+type EmulatorRule struct {
+	emulator  *TxEmulator
+	to        *common.Address // nil matches any address (and contract creation)
+	selector  []byte          // nil matches any data
+	responses []emulatorResponse
+}
+
+type emulatorResponse struct {
+	receipt *types.Receipt
+	err     error
+}
+
+type emulatorCallResponse struct {
+	results []interface{}
+	err     error
+}
+
+// EmulatedTx - transaction code tried to send while emulation was enabled
+type EmulatedTx struct {
+	From      common.Address
+	To        *common.Address
+	ChainID   *big.Int
+	GasParams Gas1559Params
+	Value     *big.Int
+	Data      []byte
+}
+
+// Selector returns 4-byte method selector of emulated transaction data (nil if data is shorter)
+func (emulatedTx EmulatedTx) Selector() []byte {
+	if len(emulatedTx.Data) < 4 {
+		return nil
+	}
+
+	return emulatedTx.Data[:4]
+}
+
+// NewTxEmulator creates emulator returning defaultReceipt for every transaction no rule matched
+func NewTxEmulator(defaultReceipt types.Receipt) *TxEmulator {
+	return &TxEmulator{
+		defaultReceipt: defaultReceipt,
+	}
+}
+
+// MethodSelector returns 4-byte selector of contract method, to be used in TxEmulator.On
+func MethodSelector(contractABI abi.ABI, methodName string) ([]byte, error) {
+	method, ok := contractABI.Methods[methodName]
+	if !ok {
+		return nil, fmt.Errorf("method \"%s\" not found in ABI", methodName)
+	}
+
+	return method.ID, nil
+}
+
+// On creates rule matching transactions sent to "to" address with data starting with "selector".
+// nil "to" or nil "selector" match anything. Rules are checked in the order they were created.
+func (emulator *TxEmulator) On(to *common.Address, selector []byte) *EmulatorRule {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	rule := &EmulatorRule{
+		emulator: emulator,
+		to:       to,
+		selector: selector,
+	}
+
+	emulator.rules = append(emulator.rules, rule)
+
+	return rule
+}
+
+// ReturnReceipt queues receipts, every matched transaction takes the next one
+func (rule *EmulatorRule) ReturnReceipt(receipts ...types.Receipt) *EmulatorRule {
+	rule.emulator.lock.Lock()
+	defer rule.emulator.lock.Unlock()
+
+	for i := range receipts {
+		receipt := receipts[i]
+		rule.responses = append(rule.responses, emulatorResponse{receipt: &receipt})
+	}
+
+	return rule
+}
+
+// ReturnError queues error, matched transaction "fails to send" with it
+func (rule *EmulatorRule) ReturnError(err error) *EmulatorRule {
+	rule.emulator.lock.Lock()
+	defer rule.emulator.lock.Unlock()
+
+	rule.responses = append(rule.responses, emulatorResponse{err: err})
+
+	return rule
+}
+
+func (rule *EmulatorRule) matches(to *common.Address, data []byte) bool {
+	if rule.to != nil && (to == nil || *rule.to != *to) {
+		return false
+	}
+
+	if rule.selector != nil && (len(data) < len(rule.selector) || !bytes.Equal(data[:len(rule.selector)], rule.selector)) {
+		return false
+	}
+
+	return true
+}
+
+// SentTransactions returns copy of all recorded would-be transactions, in order they were "sent"
+func (emulator *TxEmulator) SentTransactions() []EmulatedTx {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	return append([]EmulatedTx(nil), emulator.sent...)
+}
+
+// Reset removes all rules, recorded transactions and mocked call responses
+func (emulator *TxEmulator) Reset() {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	emulator.rules = nil
+	emulator.sent = nil
+	emulator.calls = nil
+}
+
+// emulateSend records transaction and returns response of the first matching rule which still has queued responses
+func (emulator *TxEmulator) emulateSend(emulatedTx EmulatedTx) (*types.Receipt, error) {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	emulator.sent = append(emulator.sent, emulatedTx)
+
+	for _, rule := range emulator.rules {
+		if len(rule.responses) == 0 || !rule.matches(emulatedTx.To, emulatedTx.Data) {
+			continue
+		}
+
+		response := rule.responses[0]
+		rule.responses = rule.responses[1:]
+
+		if response.err != nil {
+			return nil, response.err
+		}
+
+		receipt := *response.receipt
+		return &receipt, nil
+	}
+
+	receipt := emulator.defaultReceipt
+	return &receipt, nil
+}
+
+// emulateSend - emulation of sending, used instead of real sending when emulation is enabled
+func (eipHelper *EIP1559TransactionHelper) emulateSend(from common.Address, to *common.Address, chainID *big.Int, gasParams Gas1559Params, value *big.Int, data []byte) (*types.Receipt, error) {
+	return eipHelper.emulator.emulateSend(EmulatedTx{
+		From:      from,
+		To:        to,
+		ChainID:   chainID,
+		GasParams: gasParams,
+		Value:     value,
+		Data:      data,
+	})
+}
+
+// GetEmulator returns emulator used in emulation mode (nil if emulation is disabled)
+func (eipHelper *EIP1559TransactionHelper) GetEmulator() *TxEmulator {
+	return eipHelper.emulator
+}
+
+// emulatorCallKey - key of mocked contract call response
+type emulatorCallKey struct {
+	contract   common.Address
+	methodName string
+}
+
+// MockCall sets response of read-only call (ContractFunctionCall) of method "methodName" at "contract" in emulation mode.
+// Results are packed through ABI supplied to ContractFunctionCall (so they must match method outputs) and unpacked back,
+// exactly as real response would be. The same response is returned for every call until replaced.
+// Calls which are not mocked go to node.
+func (emulator *TxEmulator) MockCall(contract common.Address, methodName string, results ...interface{}) *TxEmulator {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	if emulator.calls == nil {
+		emulator.calls = make(map[emulatorCallKey]emulatorCallResponse)
+	}
+
+	emulator.calls[emulatorCallKey{contract: contract, methodName: methodName}] = emulatorCallResponse{results: results}
+
+	return emulator
+}
+
+// MockCallError makes read-only call of method "methodName" at "contract" fail with given error in emulation mode
+func (emulator *TxEmulator) MockCallError(contract common.Address, methodName string, err error) *TxEmulator {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	if emulator.calls == nil {
+		emulator.calls = make(map[emulatorCallKey]emulatorCallResponse)
+	}
+
+	emulator.calls[emulatorCallKey{contract: contract, methodName: methodName}] = emulatorCallResponse{err: err}
+
+	return emulator
+}
+
+// SetBaseFee sets base fee returned by GetBaseFee in emulation mode (until it is set, node is asked). Once it is set,
+// GetGasParameters calculates fees from it (as for real node) instead of returning zeros.
+func (emulator *TxEmulator) SetBaseFee(baseFee *big.Int) *TxEmulator {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	emulator.baseFee = new(big.Int).Set(baseFee)
+
+	return emulator
+}
+
+// SetBlockNumber sets block number returned by GetLatestBlockNumber in emulation mode (until it is set, node is asked)
+func (emulator *TxEmulator) SetBlockNumber(blockNumber uint64) *TxEmulator {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	emulator.blockNumber = &blockNumber
+
+	return emulator
+}
+
+// SetGasLimit sets gas limit GetGasParameters "estimates" in emulation mode (applied only if base fee is set, see SetBaseFee)
+func (emulator *TxEmulator) SetGasLimit(gasLimit uint64) *TxEmulator {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	emulator.gasLimit = gasLimit
+
+	return emulator
+}
+
+// emulateCall returns mocked response of read-only call, false if call is not mocked (and so must go to node)
+func (emulator *TxEmulator) emulateCall(contractAddress *common.Address, contractABI abi.ABI, methodName string) ([]interface{}, bool, error) {
+	if contractAddress == nil {
+		return nil, false, nil
+	}
+
+	emulator.lock.Lock()
+	response, ok := emulator.calls[emulatorCallKey{contract: *contractAddress, methodName: methodName}]
+	emulator.lock.Unlock()
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	if response.err != nil {
+		return nil, true, response.err
+	}
+
+	method, ok := contractABI.Methods[methodName]
+	if !ok {
+		return nil, true, WrapExternalError(nil, fmt.Sprintf("method \"%s\" not found in ABI", methodName))
+	}
+
+	packed, err := method.Outputs.Pack(response.results...)
+	if err != nil {
+		return nil, true, WrapExternalError(nil, fmt.Sprintf("emulation: mocked response for \"%s\" does not match ABI outputs: %s", methodName, err))
+	}
+
+	results, err := contractABI.Unpack(methodName, packed)
+
+	return results, true, err
+}
+
+func (emulator *TxEmulator) emulatedBaseFee() *big.Int {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	if emulator.baseFee == nil {
+		return nil
+	}
+
+	return new(big.Int).Set(emulator.baseFee)
+}
+
+// emulatedBlockNumber returns block number set by SetBlockNumber, false if it is not set
+func (emulator *TxEmulator) emulatedBlockNumber() (uint64, bool) {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	if emulator.blockNumber == nil {
+		return 0, false
+	}
+
+	return *emulator.blockNumber, true
+}
+
+func (emulator *TxEmulator) emulatedGasLimit() uint64 {
+	emulator.lock.Lock()
+	defer emulator.lock.Unlock()
+
+	return emulator.gasLimit
+}
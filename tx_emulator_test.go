@@ -0,0 +1,141 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testInfoABI = `[{"type":"function","name":"info","stateMutability":"view",
+	"inputs":[{"name":"id","type":"uint256"}],
+	"outputs":[{"name":"amount","type":"uint256"},{"name":"owner","type":"address"}]}]`
+
+// fakeReadBackend - node answering read paths only, counts contract calls
+type fakeReadBackend struct {
+	TxHelperBackend // Not used by read paths, calls of other methods panic
+
+	callResult []byte
+	calls      int
+}
+
+func (backend *fakeReadBackend) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(42), BaseFee: big.NewInt(7)}, nil
+}
+
+func (backend *fakeReadBackend) BlockNumber(_ context.Context) (uint64, error) {
+	return 42, nil
+}
+
+func (backend *fakeReadBackend) CallContract(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	backend.calls++
+
+	return backend.callResult, nil
+}
+
+func newTestEmulatedHelper(t *testing.T) (*EIP1559TransactionHelper, *fakeReadBackend, abi.ABI) {
+	t.Helper()
+
+	contractABI, err := abi.JSON(strings.NewReader(testInfoABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callResult, err := contractABI.Methods["info"].Outputs.Pack(big.NewInt(5), common.HexToAddress("0x00000000000000000000000000000000000000ee"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &fakeReadBackend{callResult: callResult}
+
+	txHelper, err := NewEIP1559TxHelperWithBackend(backend, WithEmulation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return txHelper, backend, contractABI
+}
+
+func TestEmulatorMockCall(t *testing.T) {
+	txHelper, backend, contractABI := newTestEmulatedHelper(t)
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+
+	txHelper.GetEmulator().MockCall(contract, "info", big.NewInt(1000), owner)
+
+	results, err := txHelper.ContractFunctionCallWithContext(context.Background(), &contract, contractABI, nil, "info", big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || results[0].(*big.Int).Cmp(big.NewInt(1000)) != 0 || results[1].(common.Address) != owner {
+		t.Fatalf("unexpected results %v", results)
+	}
+
+	if backend.calls != 0 {
+		t.Fatalf("mocked call went to node")
+	}
+
+	// Results are packed through ABI, so they must match method outputs
+	txHelper.GetEmulator().MockCall(contract, "info", "not a number", owner)
+
+	if _, err = txHelper.ContractFunctionCallWithContext(context.Background(), &contract, contractABI, nil, "info", big.NewInt(1)); err == nil {
+		t.Fatal("expected error for mocked results not matching ABI outputs")
+	}
+
+	mockedErr := errors.New("mocked failure")
+	txHelper.GetEmulator().MockCallError(contract, "info", mockedErr)
+
+	if _, err = txHelper.ContractFunctionCallWithContext(context.Background(), &contract, contractABI, nil, "info", big.NewInt(1)); !errors.Is(err, mockedErr) {
+		t.Fatalf("expected mocked error, got %v", err)
+	}
+
+	// Arguments are still checked against ABI
+	if _, err = txHelper.ContractFunctionCallWithContext(context.Background(), &contract, contractABI, nil, "info"); err == nil {
+		t.Fatal("expected error for missing arguments")
+	}
+}
+
+func TestEmulatorReadPathsFallBackToNode(t *testing.T) {
+	ctx := context.Background()
+	txHelper, backend, contractABI := newTestEmulatedHelper(t)
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+	results, err := txHelper.ContractFunctionCallWithContext(ctx, &contract, contractABI, nil, "info", big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backend.calls != 1 || results[0].(*big.Int).Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("expected not mocked call to go to node, got %v after %d calls", results, backend.calls)
+	}
+
+	if baseFee, err := txHelper.GetBaseFeeWithContext(ctx); err != nil || baseFee.Cmp(big.NewInt(7)) != 0 {
+		t.Fatalf("expected base fee 7 from node, got %v (%v)", baseFee, err)
+	}
+
+	if blockNumber, err := txHelper.GetLatestBlockNumberWithContext(ctx); err != nil || blockNumber.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("expected block number 42 from node, got %v (%v)", blockNumber, err)
+	}
+
+	// Values set in emulator take precedence, zero is a valid value too
+	txHelper.GetEmulator().SetBaseFee(big.NewInt(0)).SetBlockNumber(0)
+
+	if baseFee, err := txHelper.GetBaseFeeWithContext(ctx); err != nil || baseFee.Sign() != 0 {
+		t.Fatalf("expected emulated base fee 0, got %v (%v)", baseFee, err)
+	}
+
+	if baseFee, err := txHelper.PredictNextBaseFeeWithContext(ctx); err != nil || baseFee.Sign() != 0 {
+		t.Fatalf("expected emulated next base fee 0, got %v (%v)", baseFee, err)
+	}
+
+	if blockNumber, err := txHelper.GetLatestBlockNumberWithContext(ctx); err != nil || blockNumber.Sign() != 0 {
+		t.Fatalf("expected emulated block number 0, got %v (%v)", blockNumber, err)
+	}
+}
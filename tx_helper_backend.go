@@ -0,0 +1,56 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"math/big"
+	"time"
+)
+
+// TxHelperBackend is the set of node calls EIP1559TransactionHelper relies on.
+//
+// *ethclient.Client satisfies it, as well as go-ethereum's simulated backend client (ethclient/simulated),
+// so helper can be run against a real node, simulated chain or any hand-made fake.
+type TxHelperBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+var _ TxHelperBackend = (*ethclient.Client)(nil)
+
+// receiptPollInterval - how often receipt is requested while waiting for transaction to be mined
+const receiptPollInterval = time.Second
+
+// waitMined waits for tx to be mined and returns its receipt. Works like bind.WaitMined, but needs only TxHelperBackend.
+func waitMined(ctx context.Context, backend TxHelperBackend, txHash common.Hash) (*types.Receipt, error) {
+	queryTicker := time.NewTicker(receiptPollInterval)
+	defer queryTicker.Stop()
+
+	for {
+		receipt, err := backend.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+
+		// ethereum.NotFound means tx is not mined yet; any other error is treated as temporary
+		// (node can be unavailable for a moment), so in both cases we just retry until ctx is done
+		if !errors.Is(err, ethereum.NotFound) && ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-queryTicker.C:
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package goeth_tx_helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultGasTip int64 = 2_000_000_000
+
+// txHelperConfig collects everything NewEIP1559TxHelper needs to build a helper; it is filled by TxHelperOption functions
+type txHelperConfig struct {
+	gasTip      int64
+	feeStrategy FeeStrategy
+	txType      TxType
+
+	accessListOptimization bool
+
+	baseFeeHeadroomBlocks *uint64
+	baseFeeElasticity     uint64
+	baseFeeDenominator    uint64
+	emulation             bool
+	receiptMock           types.Receipt
+	emulator              *TxEmulator
+	dialTimeout           time.Duration
+	httpClient            *http.Client
+
+	defaultTimeout time.Duration
+	nonceManager   bool
+	speedUpPolicy  *SpeedUpPolicy
+	revertDecoding bool
+	revertABIs     []abi.ABI
+
+	preflightSimulation bool
+}
+
+// TxHelperOption configures helper created by NewEIP1559TxHelper
+type TxHelperOption func(config *txHelperConfig)
+
+func newTxHelperConfig(opts ...TxHelperOption) *txHelperConfig {
+	config := &txHelperConfig{
+		gasTip:             defaultGasTip,
+		baseFeeElasticity:  DefaultBaseFeeElasticityMultiplier,
+		baseFeeDenominator: DefaultBaseFeeChangeDenominator,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// WithGasTip sets gasTipCap (a.k.a. maxPriorityFeePerGas) used by GetGasParameters.
+//
+//	If 0 or negative value passed, default gasTip = 2_000_000_000 is applied.
+//	For L2 networks gasTip = 100_000_000 is recommended (see CreateEIP1559TxHelper for details)
+func WithGasTip(gasTip int64) TxHelperOption {
+	return func(config *txHelperConfig) {
+		if gasTip <= 0 {
+			gasTip = defaultGasTip
+		}
+
+		config.gasTip = gasTip
+	}
+}
+
+// WithFeeStrategy makes GetGasParameters take tip from given strategy (FixedTipStrategy, FeeHistoryStrategy,
+// MaxPriorityFeeStrategy or custom one) instead of fixed gasTip. nil (default) keeps fixed gasTip (see WithGasTip).
+// Strategy applies to DynamicFeeTx only, legacy and access list transactions use eth_gasPrice (see WithTxType).
+func WithFeeStrategy(strategy FeeStrategy) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.feeStrategy = strategy
+	}
+}
+
+// WithBaseFeeHeadroom replaces default maxFeePerGas = 2*baseFee + tip with: base fee of the next block (predicted from
+// the latest header, see PredictNextBaseFee), raised as if given number of 100% full blocks followed, + tip.
+// Each full block raises base fee by 12.5% on mainnet, so 0 blocks gives the cheapest fee cap, 6 blocks is roughly 2*baseFee.
+func WithBaseFeeHeadroom(fullBlocks uint64) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.baseFeeHeadroomBlocks = &fullBlocks
+	}
+}
+
+// WithBaseFeeParams sets EIP-1559 elasticity multiplier and base fee change denominator used for base fee prediction
+// (mainnet: 2 and 8, some L2s use different values). 0 means mainnet value.
+func WithBaseFeeParams(elasticity uint64, denominator uint64) TxHelperOption {
+	return func(config *txHelperConfig) {
+		if elasticity == 0 {
+			elasticity = DefaultBaseFeeElasticityMultiplier
+		}
+
+		if denominator == 0 {
+			denominator = DefaultBaseFeeChangeDenominator
+		}
+
+		config.baseFeeElasticity = elasticity
+		config.baseFeeDenominator = denominator
+	}
+}
+
+// WithTxType sets type of transactions GetGasParameters prepares parameters for (and so SendTransaction sends).
+// TxTypeAuto (default) - DynamicFeeTx on EIP-1559 chains, LegacyTx with eth_gasPrice on chains without base fee.
+// TxTypeAccessList - EIP-2930 transaction with eth_gasPrice and empty access list (fill GasParams.AccessList if needed).
+func WithTxType(txType TxType) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.txType = txType
+	}
+}
+
+// WithAccessListOptimization makes GetGasParameters generate access list (eth_createAccessList) and compare gas
+// with and without it; if the list makes transaction cheaper, it is attached to gas params (see GetGasParametersWithAccessList).
+// Also applies to gas params estimated by DryRunTransaction. If the list can't be generated, gas params go without it.
+func WithAccessListOptimization(enabled bool) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.accessListOptimization = enabled
+	}
+}
+
+// WithEmulation enables emulation of sending instead of real sending
+func WithEmulation(emulation bool) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.emulation = emulation
+	}
+}
+
+// WithReceiptMock sets the receipt SendTransaction returns when emulation is enabled (default receipt of emulator)
+func WithReceiptMock(receiptMock types.Receipt) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.receiptMock = receiptMock
+	}
+}
+
+// WithEmulator enables emulation with programmable emulator: receipts/errors per to-address and method selector,
+// recording of all would-be transactions (see TxEmulator). WithReceiptMock is ignored when emulator is given.
+func WithEmulator(emulator *TxEmulator) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.emulation = emulator != nil
+		config.emulator = emulator
+	}
+}
+
+// WithDialTimeout limits time spent on connecting to RPC node. 0 means no limit.
+func WithDialTimeout(timeout time.Duration) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.dialTimeout = timeout
+	}
+}
+
+// WithHTTPClient makes helper use given *http.Client for HTTP(S) RPC endpoints (custom transport, proxy, TLS settings, etc.)
+func WithHTTPClient(httpClient *http.Client) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.httpClient = httpClient
+	}
+}
+
+// WithDefaultTimeout sets timeout applied by context-free methods (GetGasParameters, GetBaseFee, SendTransaction,
+// ContractFunctionCall, GetLatestBlockNumber). For SendTransaction timeout covers waiting for transaction to be mined too.
+// 0 (default) means no timeout. Methods with "WithContext" suffix ignore this setting and rely on given context only.
+func WithDefaultTimeout(timeout time.Duration) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.defaultTimeout = timeout
+	}
+}
+
+// WithNonceManager enables local (per-address) nonce management: nonces are handed out by helper under lock,
+// so concurrent SendTransaction calls with the same key never get the same nonce. Nonce is requested from node
+// only for the first transaction and after failed send (resync). See also ResyncNonce and DetectNonceGaps.
+func WithNonceManager(enabled bool) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.nonceManager = enabled
+	}
+}
+
+// WithSpeedUpPolicy enables fee bumping in SendTransaction: if transaction is not mined within policy.Timeout,
+// it is re-signed with the same nonce and raised fees (see SpeedUpPolicy). nil disables fee bumping.
+func WithSpeedUpPolicy(policy *SpeedUpPolicy) TxHelperOption {
+	return func(config *txHelperConfig) {
+		if policy != nil {
+			policyCopy := *policy
+			policy = &policyCopy
+		}
+
+		config.speedUpPolicy = policy
+	}
+}
+
+// WithRevertDecoding makes SendTransaction replay reverted (receipt status 0) transaction at its block and return
+// decoded *RevertError (wrapped into ExternalErrorWrapper, use errors.As) ALONG WITH the receipt.
+// Custom errors are resolved from given ABIs (they are also used to decode estimateGas failures in GetGasParameters).
+func WithRevertDecoding(contractABIs ...abi.ABI) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.revertDecoding = true
+		config.revertABIs = append(config.revertABIs, contractABIs...)
+	}
+}
+
+// WithPreflightSimulation makes SendTransaction (and SendTransactionAsync) execute exactly the same transaction as eth_call
+// at the pending block before broadcasting. If simulation reverts, transaction is NOT sent, and error wrapping *RevertError
+// is returned. Data returned by successful simulation is returned by SendTransactionWithSimulationResult
+// and available via PendingTx.SimulationResult for SendTransactionAsync.
+func WithPreflightSimulation(enabled bool) TxHelperOption {
+	return func(config *txHelperConfig) {
+		config.preflightSimulation = enabled
+	}
+}
+
+// newEmulator returns emulator given by WithEmulator, or creates the one always returning receipt mock
+func (config *txHelperConfig) newEmulator() *TxEmulator {
+	if !config.emulation {
+		return nil
+	}
+
+	if config.emulator != nil {
+		return config.emulator
+	}
+
+	return NewTxEmulator(config.receiptMock)
+}
+
+func (config *txHelperConfig) newNonceManager() *nonceManager {
+	if !config.nonceManager {
+		return nil
+	}
+
+	return newNonceManager()
+}
+
+// registryKey builds key for TxHelperRegistry from all parameters which affect helper behaviour.
+// dialTimeout is NOT included - it matters only during connection and does not change how helper works afterwards.
+func (config *txHelperConfig) registryKey(rpcUrl string) string {
+	key := fmt.Sprintf("%s|gasTip=%d|emulation=%t|defaultTimeout=%s|nonceManager=%t", rpcUrl, config.gasTip, config.emulation, config.defaultTimeout, config.nonceManager)
+
+	if config.emulator != nil {
+		key += fmt.Sprintf("|emulator=%p", config.emulator)
+	} else if config.emulation {
+		// receiptMock is used only in emulation mode, so there is no reason to split live helpers by it
+		receiptJson, err := json.Marshal(&config.receiptMock)
+		if err != nil {
+			receiptJson = []byte(fmt.Sprintf("%+v", config.receiptMock))
+		}
+
+		key += "|receiptMock=" + crypto.Keccak256Hash(receiptJson).Hex()
+	}
+
+	if config.feeStrategy != nil {
+		key += fmt.Sprintf("|feeStrategy=%T%+v", config.feeStrategy, config.feeStrategy)
+	}
+
+	if config.txType != TxTypeAuto {
+		key += "|txType=" + config.txType.String()
+	}
+
+	if config.accessListOptimization {
+		key += "|accessListOptimization=true"
+	}
+
+	if config.baseFeeHeadroomBlocks != nil {
+		key += fmt.Sprintf("|baseFeeHeadroom=%d", *config.baseFeeHeadroomBlocks)
+	}
+
+	if config.baseFeeElasticity != DefaultBaseFeeElasticityMultiplier || config.baseFeeDenominator != DefaultBaseFeeChangeDenominator {
+		key += fmt.Sprintf("|baseFeeParams=%d/%d", config.baseFeeElasticity, config.baseFeeDenominator)
+	}
+
+	if config.httpClient != nil {
+		key += fmt.Sprintf("|httpClient=%p", config.httpClient)
+	}
+
+	if config.preflightSimulation {
+		key += "|preflightSimulation=true"
+	}
+
+	if config.revertDecoding {
+		errorSelectors := make([]string, 0)
+		for _, contractABI := range config.revertABIs {
+			for _, abiError := range contractABI.Errors {
+				errorSelectors = append(errorSelectors, abiError.ID.Hex()[:10])
+			}
+		}
+
+		sort.Strings(errorSelectors) // Errors is a map, order must not affect the key
+
+		key += "|revertDecoding=" + strings.Join(errorSelectors, ",")
+	}
+
+	if config.speedUpPolicy != nil {
+		key += fmt.Sprintf("|speedUp=%s/%d/%s/%d", config.speedUpPolicy.Timeout, config.speedUpPolicy.BumpPercent, config.speedUpPolicy.MaxGasFeeCap, config.speedUpPolicy.MaxAttempts)
+	}
+
+	return key
+}
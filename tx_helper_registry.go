@@ -8,11 +8,19 @@ import (
 var txHelperRegistrySingleInstance *TxHelperRegistry
 var initLock sync.Mutex
 
+// TxHelperRegistry keeps helpers created by NewEIP1559TxHelper / CreateEIP1559TxHelper.
+// Helpers are keyed by FULL configuration (rpcUrl + options affecting behaviour), so two calls with same rpcUrl
+// but different gasTip or emulation flag get two different helpers, instead of the second call silently receiving the first helper.
 type TxHelperRegistry struct {
 	registry   map[string]*EIP1559TransactionHelper
 	accessLock sync.Mutex
 }
 
+// GetTxHelperRegistry returns registry (singleton) which holds all helpers created by NewEIP1559TxHelper
+func GetTxHelperRegistry() *TxHelperRegistry {
+	return createTxHelperRegistry()
+}
+
 func createTxHelperRegistry() *TxHelperRegistry {
 	initLock.Lock()
 	defer initLock.Unlock()
@@ -30,11 +38,47 @@ func createTxHelperRegistry() *TxHelperRegistry {
 	return txHelperRegistrySingleInstance
 }
 
-func (thr *TxHelperRegistry) getTxHelper(rpcUrl string) *EIP1559TransactionHelper {
+// Get returns helper created for rpcUrl with exactly the same options, or nil if there is no such helper
+func (thr *TxHelperRegistry) Get(rpcUrl string, opts ...TxHelperOption) *EIP1559TransactionHelper {
+	return thr.getTxHelper(newTxHelperConfig(opts...).registryKey(rpcUrl))
+}
+
+// Remove removes helper created for rpcUrl with exactly the same options and closes its RPC connection.
+// Returns false if there was no such helper.
+func (thr *TxHelperRegistry) Remove(rpcUrl string, opts ...TxHelperOption) bool {
+	txHelper := thr.removeTxHelper(newTxHelperConfig(opts...).registryKey(rpcUrl))
+
+	if txHelper == nil {
+		return false
+	}
+
+	txHelper.closeEthClient()
+
+	return true
+}
+
+// Reset removes ALL helpers from registry and closes their RPC connections
+func (thr *TxHelperRegistry) Reset() {
+	thr.accessLock.Lock()
+	txHelpers := thr.registry
+	thr.registry = make(map[string]*EIP1559TransactionHelper)
+	thr.accessLock.Unlock()
+
+	for _, txHelper := range txHelpers {
+		txHelper.closeEthClient()
+	}
+}
+
+// Close does the same as Reset, use it on application shutdown
+func (thr *TxHelperRegistry) Close() {
+	thr.Reset()
+}
+
+func (thr *TxHelperRegistry) getTxHelper(registryKey string) *EIP1559TransactionHelper {
 	thr.accessLock.Lock()
 	defer thr.accessLock.Unlock()
 
-	txHelper, ok := thr.registry[rpcUrl]
+	txHelper, ok := thr.registry[registryKey]
 
 	if !ok {
 		return nil
@@ -43,13 +87,41 @@ func (thr *TxHelperRegistry) getTxHelper(rpcUrl string) *EIP1559TransactionHelpe
 	return txHelper
 }
 
-func (thr *TxHelperRegistry) addTxHelperToRegistry(txHelper *EIP1559TransactionHelper) {
+// addTxHelperToRegistry adds helper to registry, if helper with the same configuration is already registered,
+// that (previously registered) helper is returned and given one is NOT added
+func (thr *TxHelperRegistry) addTxHelperToRegistry(txHelper *EIP1559TransactionHelper) *EIP1559TransactionHelper {
+	thr.accessLock.Lock()
+	defer thr.accessLock.Unlock()
+
+	if registered, ok := thr.registry[txHelper.registryKey]; ok {
+		return registered
+	}
+
+	thr.registry[txHelper.registryKey] = txHelper
+
+	return txHelper
+}
+
+func (thr *TxHelperRegistry) removeTxHelper(registryKey string) *EIP1559TransactionHelper {
 	thr.accessLock.Lock()
 	defer thr.accessLock.Unlock()
 
-	if _, ok := thr.registry[txHelper.GetRpcUrl()]; ok {
-		return
+	txHelper, ok := thr.registry[registryKey]
+	if !ok {
+		return nil
 	}
 
-	thr.registry[txHelper.GetRpcUrl()] = txHelper
+	delete(thr.registry, registryKey)
+
+	return txHelper
+}
+
+// removeIfRegistered removes exactly this helper instance (not another one with the same key)
+func (thr *TxHelperRegistry) removeIfRegistered(txHelper *EIP1559TransactionHelper) {
+	thr.accessLock.Lock()
+	defer thr.accessLock.Unlock()
+
+	if registered, ok := thr.registry[txHelper.registryKey]; ok && registered == txHelper {
+		delete(thr.registry, txHelper.registryKey)
+	}
 }
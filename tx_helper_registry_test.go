@@ -0,0 +1,111 @@
+package goeth_tx_helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// HTTP RPC client does not connect until the first request, so helpers can be created for unreachable URL
+const testRegistryRpcUrl = "http://127.0.0.1:1/registry-test"
+
+func TestTxHelperRegistry(t *testing.T) {
+	registry := GetTxHelperRegistry()
+	t.Cleanup(registry.Reset)
+
+	live, err := NewEIP1559TxHelper(testRegistryRpcUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emulated, err := NewEIP1559TxHelper(testRegistryRpcUrl, WithEmulation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if live == emulated {
+		t.Fatal("live and emulated helpers for the same URL must be different")
+	}
+
+	if live.GetEmulator() != nil || emulated.GetEmulator() == nil {
+		t.Fatal("live helper must not emulate, emulated one must")
+	}
+
+	if again, _ := NewEIP1559TxHelper(testRegistryRpcUrl, WithEmulation(true)); again != emulated {
+		t.Fatal("the same configuration must return registered helper")
+	}
+
+	if registry.Get(testRegistryRpcUrl) != live || registry.Get(testRegistryRpcUrl, WithEmulation(true)) != emulated {
+		t.Fatal("Get must return helper registered with exactly the same options")
+	}
+
+	if registry.Get(testRegistryRpcUrl, WithGasTip(5)) != nil {
+		t.Fatal("Get must return nil for configuration which was not registered")
+	}
+
+	if !registry.Remove(testRegistryRpcUrl, WithEmulation(true)) {
+		t.Fatal("Remove must report removed helper")
+	}
+
+	if registry.Remove(testRegistryRpcUrl, WithEmulation(true)) {
+		t.Fatal("Remove must report false for helper which is already removed")
+	}
+
+	if registry.Get(testRegistryRpcUrl, WithEmulation(true)) != nil || registry.Get(testRegistryRpcUrl) != live {
+		t.Fatal("Remove must remove only helper with given options")
+	}
+
+	closed, err := NewEIP1559TxHelper(testRegistryRpcUrl, WithNonceManager(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed.Close()
+
+	if registry.Get(testRegistryRpcUrl, WithNonceManager(true)) != nil {
+		t.Fatal("closed helper must be removed from registry")
+	}
+
+	registry.Reset()
+
+	if registry.Get(testRegistryRpcUrl) != nil {
+		t.Fatal("Reset must remove all helpers")
+	}
+}
+
+func TestRegistryKeyRevertDecoding(t *testing.T) {
+	abiA, err := abi.JSON(strings.NewReader(`[{"type":"error","name":"ErrorA","inputs":[]},{"type":"error","name":"ErrorB","inputs":[{"name":"code","type":"uint256"}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	abiC, err := abi.JSON(strings.NewReader(`[{"type":"error","name":"ErrorC","inputs":[]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := func(opts ...TxHelperOption) string {
+		return newTxHelperConfig(opts...).registryKey(testRegistryRpcUrl)
+	}
+
+	if key(WithRevertDecoding(abiA), WithRevertDecoding(abiC)) != key(WithRevertDecoding(abiC), WithRevertDecoding(abiA)) {
+		t.Fatal("order of ABIs must not affect registry key")
+	}
+
+	if key(WithRevertDecoding(abiA, abiC)) != key(WithRevertDecoding(abiC), WithRevertDecoding(abiA)) {
+		t.Fatal("ABIs given in one or several options must give the same registry key")
+	}
+
+	if key(WithRevertDecoding(abiA)) == key(WithRevertDecoding(abiC)) {
+		t.Fatal("different ABIs must give different registry keys")
+	}
+
+	if key(WithRevertDecoding()) == key() {
+		t.Fatal("revert decoding must affect registry key")
+	}
+
+	if key() == key(WithEmulation(true)) {
+		t.Fatal("emulation must affect registry key")
+	}
+}
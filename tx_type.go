@@ -0,0 +1,170 @@
+package goeth_tx_helper
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// TxType - type of transaction helper builds (see WithTxType and Gas1559Params.TxType)
+type TxType int
+
+const (
+	TxTypeAuto       TxType = iota // DynamicFeeTx if chain supports EIP-1559 (latest block has base fee), LegacyTx otherwise
+	TxTypeDynamicFee               // EIP-1559 transaction (maxFeePerGas + maxPriorityFeePerGas)
+	TxTypeLegacy                   // Pre-EIP-2718 transaction with gasPrice (EIP-155 protected)
+	TxTypeAccessList               // EIP-2930 transaction: gasPrice + access list
+)
+
+func (txType TxType) String() string {
+	switch txType {
+	case TxTypeAuto:
+		return "auto"
+	case TxTypeDynamicFee:
+		return "dynamicFee"
+	case TxTypeLegacy:
+		return "legacy"
+	case TxTypeAccessList:
+		return "accessList"
+	default:
+		return fmt.Sprintf("TxType(%d)", int(txType))
+	}
+}
+
+// resolvedTxType - type of transaction built from gas params: explicit TxType, or, for TxTypeAuto,
+// LegacyTx if GasPrice is set and DynamicFeeTx otherwise (so hand-made Gas1559Params{GasTipCap, GasFeeCap, Gas} keep working)
+func (gasParams Gas1559Params) resolvedTxType() TxType {
+	if gasParams.TxType != TxTypeAuto {
+		return gasParams.TxType
+	}
+
+	if gasParams.GasPrice != nil {
+		return TxTypeLegacy
+	}
+
+	return TxTypeDynamicFee
+}
+
+// gasPrice - GasPrice for legacy and access list transactions, GasFeeCap is used if GasPrice is not set
+func (gasParams Gas1559Params) gasPrice() *big.Int {
+	if gasParams.GasPrice != nil {
+		return gasParams.GasPrice
+	}
+
+	return gasParams.GasFeeCap
+}
+
+// buildTransaction builds unsigned transaction of type given by gas params (see Gas1559Params.TxType)
+func buildTransaction(chainID *big.Int, nonce uint64, gasParams Gas1559Params, to *common.Address, value *big.Int, data []byte) *types.Transaction {
+	switch gasParams.resolvedTxType() {
+	case TxTypeLegacy:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasParams.gasPrice(),
+			Gas:      gasParams.Gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		})
+	case TxTypeAccessList:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasPrice:   gasParams.gasPrice(),
+			Gas:        gasParams.Gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: gasParams.AccessList,
+		})
+	default:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasTipCap:  gasParams.GasTipCap,
+			GasFeeCap:  gasParams.GasFeeCap,
+			Gas:        gasParams.Gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: gasParams.AccessList,
+		})
+	}
+}
+
+// gasParamsFromTx returns gas params transaction was built with
+func gasParamsFromTx(tx *types.Transaction) Gas1559Params {
+	gasParams := Gas1559Params{
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		Gas:        tx.Gas(),
+		AccessList: tx.AccessList(),
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType:
+		gasParams.TxType = TxTypeLegacy
+		gasParams.GasPrice = tx.GasPrice()
+	case types.AccessListTxType:
+		gasParams.TxType = TxTypeAccessList
+		gasParams.GasPrice = tx.GasPrice()
+	default:
+		gasParams.TxType = TxTypeDynamicFee
+	}
+
+	return gasParams
+}
+
+// txTypeFor resolves TxTypeAuto by latest header: chains without EIP-1559 have no base fee in block header
+func (eipHelper *EIP1559TransactionHelper) txTypeFor(header *types.Header) (TxType, error) {
+	switch eipHelper.txType {
+	case TxTypeAuto:
+		if header.BaseFee == nil {
+			return TxTypeLegacy, nil
+		}
+
+		return TxTypeDynamicFee, nil
+	case TxTypeDynamicFee:
+		if header.BaseFee == nil {
+			return TxTypeDynamicFee, fmt.Errorf("block %s has no base fee, chain does not support EIP-1559 (use TxTypeAuto or TxTypeLegacy)", header.Number)
+		}
+	}
+
+	return eipHelper.txType, nil
+}
+
+// suggestGasPrice requests eth_gasPrice, used for legacy and access list transactions
+func (eipHelper *EIP1559TransactionHelper) suggestGasPrice(ctx context.Context) (*big.Int, error) {
+	gasPricer, ok := eipHelper.backend.(ethereum.GasPricer)
+	if !ok {
+		return nil, fmt.Errorf("backend does not support eth_gasPrice, can't build %s transaction", eipHelper.txType)
+	}
+
+	gasPrice, err := gasPricer.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, WrapExternalError(err, "failed to request gas price")
+	}
+
+	return gasPrice, nil
+}
+
+// gasPriceParams - gas params of legacy / access list transaction, tip and fee cap are equal to gas price
+// (this is how types.Transaction reports them for such transactions)
+func gasPriceParams(txType TxType, gasPrice *big.Int, gas uint64) Gas1559Params {
+	gasParams := Gas1559Params{
+		TxType:    txType,
+		GasPrice:  gasPrice,
+		GasTipCap: gasPrice,
+		GasFeeCap: gasPrice,
+		Gas:       gas,
+	}
+
+	if txType == TxTypeAccessList {
+		gasParams.AccessList = types.AccessList{}
+	}
+
+	return gasParams
+}